@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"git.neds.sh/matty/entain/pkg/auth"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoles lists the role needed by each write method. Every other method is public.
+var methodRoles = map[string]string{
+	"/racing.Racing/UpdateRaceStatus":  auth.RoleTrader,
+	"/racing.Racing/UpdatePrice":       auth.RoleTrader,
+	"/racing.Racing/SetResult":         auth.RoleTrader,
+	"/racing.Racing/CreateRace":        auth.RoleAdmin,
+	"/racing.Racing/UpdateRace":        auth.RoleAdmin,
+	"/racing.Racing/DeleteRace":        auth.RoleAdmin,
+	"/sports.Sports/UpdateEventStatus": auth.RoleTrader,
+	"/sports.Sports/PushScores":        auth.RoleTrader,
+}
+
+type identityKey struct{}
+
+// identityFromContext returns the identity a request was authenticated as. Requests without a
+// token are made by an anonymous public caller.
+func identityFromContext(ctx context.Context) auth.Identity {
+	if id, ok := ctx.Value(identityKey{}).(auth.Identity); ok {
+		return id
+	}
+
+	return auth.Identity{Role: auth.RolePublic}
+}
+
+// authHandler authenticates each request before passing it on to next. Requests without an
+// Authorization header are public.
+func authHandler(verifier *auth.Verifier, mux *runtime.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := verifier.Verify(header)
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	})
+}
+
+// authorizeUnary is the unary counterpart of authorizeStream.
+func authorizeUnary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, err := authorize(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// authorizeStream refuses calls the caller's role is not allowed to make, and forwards the
+// caller's token with the others.
+func authorizeStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, err := authorize(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+// authorize checks the caller may call the method, and sets its verified token in the outgoing
+// metadata, replacing any the client sent itself.
+func authorize(ctx context.Context, method string) (context.Context, error) {
+	id := identityFromContext(ctx)
+
+	if required, ok := methodRoles[method]; ok && !auth.Allows(id.Role, required) {
+		if id.Subject == "" {
+			return nil, status.Errorf(codes.Unauthenticated, "a token with the %s role is required", required)
+		}
+
+		return nil, status.Errorf(codes.PermissionDenied, "the %s role is required", required)
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	if id.Token != "" {
+		md.Set(auth.MetadataKey, "Bearer "+id.Token)
+	} else {
+		md.Delete(auth.MetadataKey)
+	}
+
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.neds.sh/matty/entain/pkg/auth"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthHandler_RejectsBadTokens(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(keyFile, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := auth.NewVerifier("", keyFile, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := runtime.NewServeMux()
+	if err := mux.HandlePath(http.MethodGet, "/v1/whoami", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		fmt.Fprint(w, identityFromContext(r.Context()).Role)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := authHandler(verifier, mux, mux)
+
+	for header, expected := range map[string]int{
+		"":                      http.StatusOK,
+		"Bearer not-a-token":    http.StatusUnauthorized,
+		"Basic amFuZTpzZWNyZXQ": http.StatusUnauthorized,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/v1/whoami", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != expected {
+			t.Errorf("Expected status %d for %q but got %d", expected, header, w.Code)
+		}
+		if w.Code == http.StatusOK && w.Body.String() != auth.RolePublic {
+			t.Errorf("Expected role %s but got %s", auth.RolePublic, w.Body.String())
+		}
+	}
+}
+
+func TestAuthorize_Roles(t *testing.T) {
+	as := func(subject, role string) context.Context {
+		return context.WithValue(context.Background(), identityKey{}, auth.Identity{Subject: subject, Role: role, Token: subject + "-token"})
+	}
+
+	for _, c := range []struct {
+		ctx      context.Context
+		method   string
+		expected codes.Code
+	}{
+		{context.Background(), "/racing.Racing/ListRaces", codes.OK},
+		{context.Background(), "/racing.Racing/UpdateRaceStatus", codes.Unauthenticated},
+		{as("joe", auth.RolePublic), "/sports.Sports/UpdateEventStatus", codes.PermissionDenied},
+		{as("jane", auth.RoleTrader), "/sports.Sports/UpdateEventStatus", codes.OK},
+		{as("jane", auth.RoleTrader), "/racing.Racing/CreateRace", codes.PermissionDenied},
+		{as("ops", auth.RoleAdmin), "/racing.Racing/CreateRace", codes.OK},
+		{as("ops", auth.RoleAdmin), "/racing.Racing/SetResult", codes.OK},
+	} {
+		if _, err := authorize(c.ctx, c.method); status.Code(err) != c.expected {
+			t.Errorf("Expected error code %v for %v calling %s but got %v", c.expected, identityFromContext(c.ctx), c.method, status.Code(err))
+		}
+	}
+
+	// The verified token replaces any the client sent itself
+	ctx := metadata.AppendToOutgoingContext(as("jane", auth.RoleTrader), auth.MetadataKey, "Bearer ops-token")
+	ctx, err := authorize(ctx, "/racing.Racing/UpdatePrice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if token := md.Get(auth.MetadataKey); len(token) != 1 || token[0] != "Bearer jane-token" {
+		t.Errorf("Expected jane's token in the metadata but got %v", md)
+	}
+
+	// Anonymous callers send no token
+	ctx, err = authorize(metadata.AppendToOutgoingContext(context.Background(), auth.MetadataKey, "Bearer ops-token"), "/racing.Racing/ListRaces")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md, _ = metadata.FromOutgoingContext(ctx)
+	if len(md.Get(auth.MetadataKey)) != 0 {
+		t.Errorf("Expected an anonymous caller without a token in the metadata but got %v", md)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"git.neds.sh/matty/entain/api/proto/racing"
+	"git.neds.sh/matty/entain/api/proto/sports"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// maxCachedBodySize is the largest request body read to find a cached response. Larger
+	// requests are not cached.
+	maxCachedBodySize = 64 << 10
+	// watchRetryInterval is how long to wait before watching a backend again after its stream
+	// broke.
+	watchRetryInterval = time.Second
+)
+
+var (
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_cache_requests_total",
+		Help: "Requests to cached routes, by route and whether they were a hit, a miss or not cacheable.",
+	}, []string{"route", "result"})
+
+	cacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_cache_invalidated_entries_total",
+		Help: "Cached responses dropped before their TTL because their backend reported a change, by backend.",
+	}, []string{"backend"})
+)
+
+// cachedRoute is a read route whose responses are cached. Its body is read into request to
+// build the cache key, and the responses are dropped when backend reports a change.
+type cachedRoute struct {
+	backend string
+	request func() proto.Message
+}
+
+// cachedRoutes lists the routes whose responses are cached, by path. They are all POST routes
+// taking their request in the body.
+var cachedRoutes = map[string]cachedRoute{
+	"/v1/list-races":         {"racing", func() proto.Message { return &racing.ListRacesRequest{} }},
+	"/v1/list-meetings":      {"racing", func() proto.Message { return &racing.ListMeetingsRequest{} }},
+	"/v1/list-results":       {"racing", func() proto.Message { return &racing.ListResultsRequest{} }},
+	"/v1/list-sports-events": {"sports", func() proto.Message { return &sports.ListEventsRequest{} }},
+	"/v1/list-sports":        {"sports", func() proto.Message { return &sports.ListSportsRequest{} }},
+	"/v1/list-competitions":  {"sports", func() proto.Message { return &sports.ListCompetitionsRequest{} }},
+}
+
+// cacheEntry is a response kept in the cache.
+type cacheEntry struct {
+	backend string
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// responseCache keeps the responses of the cached routes for a short time, in memory.
+type responseCache struct {
+	ttl         time.Duration
+	maxEntries  int
+	now         func() time.Time
+	mu          sync.Mutex
+	entries     map[string]*cacheEntry
+	generations map[string]uint64
+}
+
+// newResponseCache keeps up to maxEntries responses for ttl each. A ttl of 0 turns caching off,
+// though ETags are still sent.
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	return &responseCache{
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		now:         time.Now,
+		entries:     make(map[string]*cacheEntry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// cacheKey builds the key of a request from its route and its body, read into the route's
+// request message. Bodies asking for the same thing, such as {} and {"filter": {}}, or with
+// fields in another order or case, share a key. ok is false for bodies the gateway would refuse.
+func cacheKey(path string, route cachedRoute, body []byte) (key string, ok bool) {
+	in := route.request()
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, in); err != nil {
+			return "", false
+		}
+	}
+
+	pruneEmpty(in.ProtoReflect())
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(in)
+	if err != nil {
+		return "", false
+	}
+
+	return path + "\x00" + string(data), true
+}
+
+// pruneEmpty clears the message fields left empty, such as a filter of {}, as the services
+// read them the same as unset ones.
+func pruneEmpty(m protoreflect.Message) {
+	m.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if field.Message() != nil && field.Cardinality() != protoreflect.Repeated {
+			pruneEmpty(value.Message())
+
+			if proto.Size(value.Message().Interface()) == 0 {
+				m.Clear(field)
+			}
+		}
+
+		return true
+	})
+}
+
+// get returns the fresh entry for key, if any, along with the generation of the backend the
+// entry would be stored under.
+func (c *responseCache) get(key, backend string) (*cacheEntry, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && c.now().After(entry.expires) {
+		delete(c.entries, key)
+		entry = nil
+	}
+
+	return entry, c.generations[backend]
+}
+
+// put stores the entry, unless its backend changed since generation was read, as the response
+// may be from before the change. When the cache is full, expired entries are dropped first, and
+// the entry is not stored if none were.
+func (c *responseCache) put(key string, entry *cacheEntry, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[entry.backend] != generation {
+		return
+	}
+
+	if len(c.entries) >= c.maxEntries {
+		now := c.now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+
+	entry.expires = c.now().Add(c.ttl)
+	c.entries[key] = entry
+}
+
+// invalidate drops the entries of a backend that reported a change.
+func (c *responseCache) invalidate(backend string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[backend]++
+	for key, entry := range c.entries {
+		if entry.backend == backend {
+			delete(c.entries, key)
+			cacheInvalidations.WithLabelValues(backend).Inc()
+		}
+	}
+}
+
+// handler serves the cached routes from the cache when it can, before passing requests on to next.
+// Their responses carry an ETag, and a request whose If-None-Match holds it gets 304 Not Modified.
+func (c *responseCache) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := cachedRoutes[r.URL.Path]
+		if !ok || r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxCachedBodySize+1))
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The body is read again by the route
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+		key, ok := cacheKey(r.URL.Path, route, body)
+		if !ok || len(body) > maxCachedBodySize {
+			cacheRequests.WithLabelValues(r.URL.Path, "bypass").Inc()
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry, generation := c.get(key, route.backend)
+		if entry != nil {
+			cacheRequests.WithLabelValues(r.URL.Path, "hit").Inc()
+			w.Header().Set("X-Cache", "HIT")
+			writeEntry(w, r, entry)
+			return
+		}
+
+		cacheRequests.WithLabelValues(r.URL.Path, "miss").Inc()
+
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status != http.StatusOK {
+			copyHeader(w.Header(), rec.header)
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(rec.body.Bytes())
+		entry = &cacheEntry{
+			backend: route.backend,
+			header:  rec.header,
+			body:    rec.body.Bytes(),
+			etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+		}
+
+		if c.ttl > 0 {
+			c.put(key, entry, generation)
+		}
+
+		w.Header().Set("X-Cache", "MISS")
+		writeEntry(w, r, entry)
+	})
+}
+
+// writeEntry writes a response with its ETag, or 304 Not Modified when the client has it already.
+func writeEntry(w http.ResponseWriter, r *http.Request, entry *cacheEntry) {
+	copyHeader(w.Header(), entry.header)
+	w.Header().Set("ETag", entry.etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(entry.body)
+}
+
+// etagMatches reports whether an If-None-Match header holds the ETag, or is *.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyHeader(dst, src http.Header) {
+	for name, values := range src {
+		dst[name] = values
+	}
+}
+
+// bufferedResponse keeps a response in memory, so its ETag can be sent before its body.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+// watchBackends drops the cached responses of a backend each time it reports a change, until ctx
+// is done. Both backends are watched through WatchChanges, which only signals that something
+// changed, races closing with the clock included, rather than sending what did.
+func (c *responseCache) watchBackends(ctx context.Context, racingEndpoint, sportsEndpoint string, opts []grpc.DialOption) error {
+	racingConn, err := dialBackend(ctx, racingEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	sportsConn, err := dialBackend(ctx, sportsEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	racingClient := racing.NewRacingClient(racingConn)
+	sportsClient := sports.NewSportsClient(sportsConn)
+
+	go c.watch(ctx, "racing", func(ctx context.Context) (func() error, error) {
+		stream, err := racingClient.WatchChanges(ctx, &racing.WatchChangesRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		return func() error {
+			_, err := stream.Recv()
+			return err
+		}, nil
+	})
+
+	go c.watch(ctx, "sports", func(ctx context.Context) (func() error, error) {
+		stream, err := sportsClient.WatchChanges(ctx, &sports.WatchChangesRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		return func() error {
+			_, err := stream.Recv()
+			return err
+		}, nil
+	})
+
+	return nil
+}
+
+// watch opens a stream of the backend's changes and invalidates its entries on every change. A
+// broken stream is opened again, dropping the entries as changes may have been missed meanwhile.
+// The first signal of a stream that was opened again drops them once more, covering the changes
+// made until the backend was watching.
+func (c *responseCache) watch(ctx context.Context, backend string, open func(context.Context) (func() error, error)) {
+	failing := false
+
+	for {
+		recv, err := open(ctx)
+		for err == nil {
+			if err = recv(); err == nil {
+				c.invalidate(backend)
+				failing = false
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// An outage is only logged once
+		if !failing {
+			log.Printf("watching %s for changes: %s\n", backend, err)
+			failing = true
+		}
+		c.invalidate(backend)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
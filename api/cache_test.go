@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheKey_Normalizes(t *testing.T) {
+	route := cachedRoutes["/v1/list-races"]
+
+	key := func(body string) string {
+		t.Helper()
+
+		k, ok := cacheKey("/v1/list-races", route, []byte(body))
+		if !ok {
+			t.Fatalf("Expected %s to be cacheable", body)
+		}
+
+		return k
+	}
+
+	if key("") != key(`{}`) || key(`{}`) != key(`{"filter": {}}`) {
+		t.Error("Expected empty bodies to share a key")
+	}
+
+	if key(`{"filter": {"meetingIds": [1], "visible": true}}`) != key(`{"filter":{"visible":true,"meeting_ids":[1]}}`) {
+		t.Error("Expected the same filter to share a key whatever the field order and case")
+	}
+
+	if key(`{"filter": {"meetingIds": [1]}}`) == key(`{"filter": {"meetingIds": [2]}}`) {
+		t.Error("Expected other filters to have other keys")
+	}
+
+	// Unset and false optional fields ask for different races
+	if key(`{"filter": {"visible": false}}`) == key(`{}`) {
+		t.Error("Expected visible false to have its own key")
+	}
+
+	if _, ok := cacheKey("/v1/list-races", route, []byte(`{"filter": `)); ok {
+		t.Error("Expected a malformed body not to be cacheable")
+	}
+}
+
+func TestResponseCache_Handler(t *testing.T) {
+	now := time.Date(2030, 4, 5, 6, 0, 0, 0, time.UTC)
+
+	cache := newResponseCache(2*time.Second, 10)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	status := http.StatusOK
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	})
+	handler := cache.handler(backend)
+
+	serve := func(path, body, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	// The first request goes to the backend
+	w := serve("/v1/list-races", `{}`, "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "MISS" || etag == "" || w.Body.String() != `{"call":1}` {
+		t.Fatalf("Expected a miss with an ETag but got %d %v %s", w.Code, w.Header(), w.Body)
+	}
+
+	// The same request, however written, is answered from the cache
+	w = serve("/v1/list-races", `{"filter": {}}`, "")
+	if w.Header().Get("X-Cache") != "HIT" || w.Body.String() != `{"call":1}` || w.Header().Get("ETag") != etag || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a hit for the first response but got %v %s", w.Header(), w.Body)
+	}
+
+	// Clients holding the response get 304
+	for _, ifNoneMatch := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		if w = serve("/v1/list-races", `{}`, ifNoneMatch); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("Expected 304 for If-None-Match %s but got %d", ifNoneMatch, w.Code)
+		}
+	}
+	if w = serve("/v1/list-races", `{}`, `"other"`); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for another ETag but got %d", w.Code)
+	}
+
+	// Entries expire after the TTL
+	now = now.Add(3 * time.Second)
+	if w = serve("/v1/list-races", `{}`, ""); w.Header().Get("X-Cache") != "MISS" || w.Body.String() != `{"call":2}` {
+		t.Errorf("Expected the expired entry to be fetched again but got %v %s", w.Header(), w.Body)
+	}
+
+	// A change reported by the backend drops its entries early, but not the other backend's
+	serve("/v1/list-sports", `{}`, "")
+	cache.invalidate("racing")
+	if w = serve("/v1/list-races", `{}`, ""); w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("Expected the invalidated entry to be fetched again but got %v", w.Header())
+	}
+	if w = serve("/v1/list-sports", `{}`, ""); w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("Expected the sports entry to be kept but got %v", w.Header())
+	}
+
+	// Errors, malformed bodies and other routes are passed through
+	status = http.StatusServiceUnavailable
+	for _, path := range []string{"/v1/list-meetings", "/v1/list-meetings"} {
+		if w = serve(path, `{}`, ""); w.Code != http.StatusServiceUnavailable || w.Header().Get("ETag") != "" {
+			t.Errorf("Expected the error to be passed through uncached but got %d %v", w.Code, w.Header())
+		}
+	}
+
+	status = http.StatusOK
+	before := calls
+	serve("/v1/list-races", `{"filter": `, "")
+	serve("/v1/race/1/status", `{}`, "")
+	if calls != before+2 {
+		t.Errorf("Expected both requests to reach the backend but %d did", calls-before)
+	}
+}
+
+func TestResponseCache_Watch(t *testing.T) {
+	cache := newResponseCache(time.Minute, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The stream reports two changes then breaks, the next one stays open
+	opened := make(chan struct{}, 2)
+	changes := 2
+	go cache.watch(ctx, "racing", func(ctx context.Context) (func() error, error) {
+		opened <- struct{}{}
+
+		return func() error {
+			if changes > 0 {
+				changes--
+				return nil
+			}
+
+			if len(opened) < 2 {
+				return errors.New("stream broke")
+			}
+
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil
+	})
+
+	// One invalidation per change and one for the broken stream
+	deadline := time.After(5 * time.Second)
+	for {
+		cache.mu.Lock()
+		generation := cache.generations["racing"]
+		cache.mu.Unlock()
+
+		if generation == 3 && len(opened) == 2 {
+			break
+		}
+
+		select {
+		case <-deadline:
+			t.Fatalf("Expected 3 invalidations and the stream to be opened again but got %d, opened %d times", generation, len(opened))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
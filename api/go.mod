@@ -3,11 +3,18 @@ module git.neds.sh/matty/entain/api
 go 1.16
 
 require (
+	git.neds.sh/matty/entain/pkg v0.0.0-00010101000000-000000000000
 	github.com/bufbuild/buf v0.37.0 // indirect
 	github.com/golang/protobuf v1.5.3
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.15.2
+	github.com/prometheus/client_golang v1.15.1
+	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.40.0
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.40.0
+	golang.org/x/time v0.3.0
 	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1
 	google.golang.org/grpc v1.54.0
 	google.golang.org/grpc/cmd/protoc-gen-go-grpc v1.3.0
 	google.golang.org/protobuf v1.30.0
 )
+
+replace git.neds.sh/matty/entain/pkg => ../pkg
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"git.neds.sh/matty/entain/api/proto/racing"
+	"git.neds.sh/matty/entain/api/proto/sports"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// unreachableStatus is the status of a backend whose health could not be checked.
+const unreachableStatus = "UNREACHABLE"
+
+// healthBackend is a backend service whose health the gateway checks.
+type healthBackend struct {
+	name    string
+	service string
+	client  healthpb.HealthClient
+}
+
+// backendHealth is the health of a backend in a health response. Error tells why a backend is
+// unreachable.
+type backendHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthResponse is SERVING when every backend is, and NOT_SERVING otherwise.
+type healthResponse struct {
+	Status   string                   `json:"status"`
+	Backends map[string]backendHealth `json:"backends"`
+}
+
+// healthChecker asks the backends for their health through the standard gRPC health service.
+type healthChecker struct {
+	backends []healthBackend
+	timeout  time.Duration
+}
+
+// registerHealth serves GET /healthz and GET /readyz. Both report the health of each backend;
+// /healthz answers 200 OK as long as the gateway runs, while /readyz answers 503 Service
+// Unavailable until every backend is serving.
+func registerHealth(ctx context.Context, mux *runtime.ServeMux, routes *routeTable, racingEndpoint, sportsEndpoint string, timeout time.Duration, opts []grpc.DialOption) error {
+	racingConn, err := dialBackend(ctx, racingEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	sportsConn, err := dialBackend(ctx, sportsEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	h := &healthChecker{
+		backends: []healthBackend{
+			{name: "racing", service: racing.Racing_ServiceDesc.ServiceName, client: healthpb.NewHealthClient(racingConn)},
+			{name: "sports", service: sports.Sports_ServiceDesc.ServiceName, client: healthpb.NewHealthClient(sportsConn)},
+		},
+		timeout: timeout,
+	}
+
+	if err := routes.handlePath(mux, http.MethodGet, "/healthz", h.serve(false)); err != nil {
+		return err
+	}
+
+	return routes.handlePath(mux, http.MethodGet, "/readyz", h.serve(true))
+}
+
+// serve writes the health of the backends, with 503 Service Unavailable when ready is asked for
+// and a backend is not serving.
+func (h *healthChecker) serve(ready bool) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		resp := h.check(r.Context())
+
+		code := http.StatusOK
+		if ready && resp.Status != healthpb.HealthCheckResponse_SERVING.String() {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// check asks every backend for its health at once, each within the timeout.
+func (h *healthChecker) check(ctx context.Context) *healthResponse {
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	resp := &healthResponse{
+		Status:   healthpb.HealthCheckResponse_SERVING.String(),
+		Backends: make(map[string]backendHealth, len(h.backends)),
+	}
+
+	for _, backend := range h.backends {
+		backend := backend
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			var health backendHealth
+
+			res, err := backend.client.Check(ctx, &healthpb.HealthCheckRequest{Service: backend.service})
+			if err != nil {
+				health = backendHealth{Status: unreachableStatus, Error: status.Convert(err).Message()}
+			} else {
+				health = backendHealth{Status: res.Status.String()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			resp.Backends[backend.name] = health
+			if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+				resp.Status = healthpb.HealthCheckResponse_NOT_SERVING.String()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return resp
+}
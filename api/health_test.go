@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// stubHealth answers health checks with a fixed status, or fails.
+type stubHealth struct {
+	healthpb.HealthClient
+	status healthpb.HealthCheckResponse_ServingStatus
+	err    error
+}
+
+func (s *stubHealth) Check(ctx context.Context, in *healthpb.HealthCheckRequest, _ ...grpc.CallOption) (*healthpb.HealthCheckResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &healthpb.HealthCheckResponse{Status: s.status}, nil
+}
+
+func TestHealthChecker_Probes(t *testing.T) {
+	racingHealth := &stubHealth{status: healthpb.HealthCheckResponse_SERVING}
+	sportsHealth := &stubHealth{status: healthpb.HealthCheckResponse_SERVING}
+
+	h := &healthChecker{
+		backends: []healthBackend{
+			{name: "racing", service: "racing.Racing", client: racingHealth},
+			{name: "sports", service: "sports.Sports", client: sportsHealth},
+		},
+		timeout: time.Second,
+	}
+
+	probe := func(ready bool) (int, healthResponse) {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		h.serve(ready)(w, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+		var resp healthResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		return w.Code, resp
+	}
+
+	if code, resp := probe(true); code != http.StatusOK || resp.Status != "SERVING" {
+		t.Errorf("Expected ready when both backends serve but got %d %+v", code, resp)
+	}
+
+	// A backend still initialising or unreachable makes the gateway not ready, though still alive
+	racingHealth.status = healthpb.HealthCheckResponse_NOT_SERVING
+	sportsHealth.err = status.Error(codes.Unavailable, "connection refused")
+
+	code, resp := probe(true)
+	if code != http.StatusServiceUnavailable || resp.Status != "NOT_SERVING" {
+		t.Errorf("Expected not ready but got %d %+v", code, resp)
+	}
+	if resp.Backends["racing"].Status != "NOT_SERVING" {
+		t.Errorf("Expected racing not serving but got %+v", resp.Backends["racing"])
+	}
+	if resp.Backends["sports"] != (backendHealth{Status: unreachableStatus, Error: "connection refused"}) {
+		t.Errorf("Expected sports unreachable but got %+v", resp.Backends["sports"])
+	}
+
+	if code, _ := probe(false); code != http.StatusOK {
+		t.Errorf("Expected the gateway alive but got %d", code)
+	}
+}
+
+func TestRegisterHealth_Routes(t *testing.T) {
+	mux := runtime.NewServeMux()
+	if err := registerHealth(context.Background(), mux, &routeTable{}, "localhost:0", "localhost:0", 100*time.Millisecond, []grpc.DialOption{grpc.WithInsecure()}); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]int{"/healthz": http.StatusOK, "/readyz": http.StatusServiceUnavailable} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != expected {
+			t.Errorf("Expected %d for %s without backends but got %d", expected, path, w.Code)
+		}
+	}
+}
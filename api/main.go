@@ -3,21 +3,57 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"git.neds.sh/matty/entain/api/proto/racing"
 	"git.neds.sh/matty/entain/api/proto/sports"
+	"git.neds.sh/matty/entain/pkg/auth"
+	"git.neds.sh/matty/entain/pkg/tracing"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 var (
 	apiEndpoint        = flag.String("api-endpoint", "localhost:8000", "API endpoint")
 	grpcRacingEndpoint = flag.String("grpc-endpoint", "localhost:9000", "gRPC server endpoint")
 	grpcSportsEndpoint = flag.String("grpc-sport-endpoint", "localhost:9001", "gRPC Sports server endpoint")
+	nextToGoTimeout    = flag.Duration("next-to-go-timeout", time.Second, "Time each backend has to answer a next to go request")
+	healthTimeout      = flag.Duration("health-timeout", time.Second, "Time each backend has to answer a health check")
+	jwksFile           = flag.String("jwks-file", "", "JWKS file holding the keys bearer tokens are signed with")
+	jwtKeyFile         = flag.String("jwt-key-file", "", "PEM public key or HMAC secret bearer tokens are signed with, instead of a JWKS file")
+	jwtIssuer          = flag.String("jwt-issuer", "", "Issuer bearer tokens must be issued by, if set")
+	jwtAudience        = flag.String("jwt-audience", "", "Audience bearer tokens must be issued for, if set")
+	otlpEndpoint       = flag.String("otlp-endpoint", "", "OTLP gRPC endpoint the trace spans are exported to, e.g. localhost:4317")
+	otlpInsecure       = flag.Bool("otlp-insecure", false, "Export the trace spans to the OTLP endpoint without TLS")
+	traceFile          = flag.String("trace-file", "", "File the trace spans are written to as JSON lines, - for stdout")
+	shutdownGrace      = flag.Duration("shutdown-grace-period", 15*time.Second, "Time in-flight requests have to finish once a SIGINT or SIGTERM is received")
+	metricsEndpoint    = flag.String("metrics-endpoint", "localhost:8100", "Endpoint serving the Prometheus metrics on /metrics")
+	rateLimitRate      = flag.Float64("rate-limit-rate", 20, "Requests per second each client may make to a route without its own rate limit")
+	rateLimitBurst     = flag.Int("rate-limit-burst", 40, "Requests each client may burst to a route without its own rate limit")
+	rateLimitForwarded = flag.Bool("rate-limit-forwarded-for", false, "Tell clients without a known API key apart by X-Forwarded-For, when behind a trusted proxy")
+	rateLimitKeysFile  = flag.String("rate-limit-api-keys-file", "", "File listing the API keys, one per line, whose clients get buckets of their own")
+	rateLimitBuckets   = flag.Int("rate-limit-max-clients", 100000, "Buckets kept at most, new clients share one per route beyond it")
+	cacheTTL           = flag.Duration("cache-ttl", 2*time.Second, "Time the responses of the list routes are cached for, 0 to turn caching off")
+	cacheMaxEntries    = flag.Int("cache-max-entries", 1000, "Responses kept in the cache at most")
+	rateLimits         rateLimitRules
 )
 
+func init() {
+	flag.Var(&rateLimits, "rate-limit", "Rate limit of a route as ROUTE=RATE:BURST, e.g. /v1/list-races=5:10. May be repeated")
+}
+
 func main() {
 	flag.Parse()
 
@@ -27,16 +63,56 @@ func main() {
 }
 
 func run() error {
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The backend connections outlive the signal, for the requests in flight to finish
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	flushTracing, err := tracing.Setup(ctx, "api", *otlpEndpoint, *otlpInsecure, *traceFile)
+	if err != nil {
+		return err
+	}
+	defer flushTracing()
+
+	verifier, err := auth.NewVerifier(*jwksFile, *jwtKeyFile, *jwtIssuer, *jwtAudience)
+	if err != nil {
+		return err
+	}
+
+	// Every backend call is authorized against the caller's role, and carries its identity along
+	// with the trace context of the request. Health checks are not traced.
+	traced := otelgrpc.WithInterceptorFilter(filters.Not(filters.HealthCheck()))
+	opts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithChainUnaryInterceptor(otelgrpc.UnaryClientInterceptor(traced), authorizeUnary),
+		grpc.WithChainStreamInterceptor(otelgrpc.StreamClientInterceptor(traced), authorizeStream),
+	}
+
+	// Metrics and spans are named by the route a request resolves to, so made up paths do not
+	// each get their own series or span name
+	routes := &routeTable{}
+	routes.addServices(racing.File_racing_racing_proto, sports.File_sports_sports_proto)
+
 	mux := runtime.NewServeMux()
 	if err := racing.RegisterRacingHandlerFromEndpoint(
 		ctx,
 		mux,
 		*grpcRacingEndpoint,
-		[]grpc.DialOption{grpc.WithInsecure()},
+		opts,
+	); err != nil {
+		return err
+	}
+
+	// WatchRaces is a server stream, served to HTTP clients as Server-Sent Events
+	if err := registerWatchRaces(
+		ctx,
+		mux,
+		routes,
+		*grpcRacingEndpoint,
+		opts,
 	); err != nil {
 		return err
 	}
@@ -46,12 +122,146 @@ func run() error {
 		ctx,
 		mux,
 		*grpcSportsEndpoint,
-		[]grpc.DialOption{grpc.WithInsecure()},
+		opts,
 	); err != nil {
 		return err
 	}
 
+	// WatchEvent is a server stream too, served the same way
+	if err := registerWatchEvent(
+		ctx,
+		mux,
+		routes,
+		*grpcSportsEndpoint,
+		opts,
+	); err != nil {
+		return err
+	}
+
+	// Next to go merges both backends, so it is served by the gateway itself
+	if err := registerNextToGo(
+		ctx,
+		mux,
+		routes,
+		*grpcRacingEndpoint,
+		*grpcSportsEndpoint,
+		*nextToGoTimeout,
+		opts,
+	); err != nil {
+		return err
+	}
+
+	// Probes see the health of both backends
+	if err := registerHealth(
+		ctx,
+		mux,
+		routes,
+		*grpcRacingEndpoint,
+		*grpcSportsEndpoint,
+		*healthTimeout,
+		opts,
+	); err != nil {
+		return err
+	}
+
+	if *rateLimitRate <= 0 || *rateLimitBurst < 1 {
+		return fmt.Errorf("rate limit must allow at least 1 request, not %v per second with a burst of %d", *rateLimitRate, *rateLimitBurst)
+	}
+
+	// List responses are cached until their backend reports a change, or for the TTL at most
+	cache := newResponseCache(*cacheTTL, *cacheMaxEntries)
+	if *cacheTTL > 0 {
+		if err := cache.watchBackends(ctx, *grpcRacingEndpoint, *grpcSportsEndpoint, opts); err != nil {
+			return err
+		}
+	}
+
+	if *rateLimitBuckets < 1 {
+		return fmt.Errorf("rate limit must keep at least 1 bucket, not %d", *rateLimitBuckets)
+	}
+
+	var apiKeys map[string]bool
+	if *rateLimitKeysFile != "" {
+		if apiKeys, err = loadAPIKeys(*rateLimitKeysFile); err != nil {
+			return err
+		}
+	}
+
+	// Clients are limited before their token is checked, so bad tokens cannot be tried endlessly
+	limiter := newRateLimiter(rateLimits, rateLimit{rate: rate.Limit(*rateLimitRate), burst: *rateLimitBurst}, *rateLimitForwarded, apiKeys, *rateLimitBuckets)
+
+	metricsServer := newMetricsServer(*metricsEndpoint)
+	go func() {
+		log.Printf("Metrics listening on: %s\n", *metricsEndpoint)
+
+		if err := metricsServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Printf("failed serving metrics: %s\n", err)
+		}
+	}()
+	defer metricsServer.Close()
+
+	handler := metricsHandler(routes, limiter.handler(mux, authHandler(verifier, mux, cache.handler(mux))))
+
+	// Each request starts a trace, or continues the one in its traceparent header, except probes
+	handler = otelhttp.NewHandler(handler, "api",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return methodLabel(r.Method) + " " + routes.resolve(r)
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+		}),
+	)
+
+	server := newServer(*apiEndpoint, handler)
+
 	log.Printf("API server listening on: %s\n", *apiEndpoint)
 
-	return http.ListenAndServe(*apiEndpoint, mux)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-signals.Done():
+	}
+
+	// A second signal stops the gateway at once
+	stop()
+
+	log.Printf("Shutting down, waiting up to %s for in-flight requests\n", *shutdownGrace)
+
+	shutdownGracefully(server, *shutdownGrace)
+
+	log.Printf("API server stopped\n")
+
+	return nil
 }
+
+// dialBackend opens a connection for a hand-written handler, closing it once ctx is done.
+func dialBackend(ctx context.Context, endpoint string, opts []grpc.DialOption) (*grpc.ClientConn, error) {
+	conn, err := grpc.DialContext(ctx, endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	return conn, nil
+}
+
+// newMetricsServer serves the Prometheus metrics on their own endpoint, away from the API.
+func newMetricsServer(endpoint string) *http.Server {
+	metrics := http.NewServeMux()
+	metrics.Handle("/metrics", promhttp.Handler())
+
+	return &http.Server{Addr: endpoint, Handler: metrics}
+}
+
+// protoJSON marshals messages for the hand-written handlers the way the generated routes do,
+// emitting fields left at their zero value, such as an OPEN status.
+var protoJSON = protojson.MarshalOptions{EmitUnpopulated: true}
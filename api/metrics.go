@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// otherMethod is the method label of requests made with a non-standard method, so that made up
+// methods do not each get their own series either.
+const otherMethod = "OTHER"
+
+// standardMethods are the methods labelled as they are.
+var standardMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_http_requests_total",
+		Help: "HTTP requests served, by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "api_http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests, by route and method. Event streams are not timed.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+// metricsHandler counts and times the requests served by next, by the route they resolve to.
+// The route is resolved up front, so requests refused by the other middleware are labelled the
+// same way.
+func metricsHandler(routes *routeTable, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		route, method := routes.resolve(r), methodLabel(r.Method)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		httpRequests.WithLabelValues(route, method, strconv.Itoa(rec.status)).Inc()
+
+		// Event streams last as long as the client watches
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+			httpDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+		}
+	})
+}
+
+// methodLabel names a request method, or otherMethod when it is not a standard one.
+func methodLabel(method string) string {
+	if !standardMethods[method] {
+		return otherMethod
+	}
+
+	return method
+}
+
+// statusRecorder keeps the status code of a response as it is written.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Flush sends event streams on as they are written.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
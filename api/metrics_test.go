@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// counterValue reads a counter from the default registry by its labels, 0 if it was never set.
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestMetricsHandler_CountsRequests(t *testing.T) {
+	routes := &routeTable{}
+
+	mux := runtime.NewServeMux()
+	if err := routes.handlePath(mux, http.MethodGet, "/v1/race/{id}", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		w.WriteHeader(http.StatusTeapot)
+		fmt.Fprint(w, "race")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Requests under /x/ are refused before they reach the mux, as the rate limiter and the
+	// authenticator do
+	handler := metricsHandler(routes, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/x/") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+
+	count := func(route, method, code string) float64 {
+		return counterValue(t, "api_http_requests_total", map[string]string{"route": route, "method": method, "code": code})
+	}
+	beforeMatched := count("/v1/race/{id}", http.MethodGet, "418")
+	beforeUnmatched := count(unmatchedRoute, http.MethodGet, "404")
+	beforeRefused := count(unmatchedRoute, http.MethodGet, "429")
+	beforeOther := count(unmatchedRoute, otherMethod, "429")
+
+	for _, path := range []string{"/v1/race/1", "/v1/race/2", "/wp-login.php", "/x/abc1", "/x/abc2", "/x/abc3"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("MADEUP", "/x/abc4", nil))
+
+	if got := count("/v1/race/{id}", http.MethodGet, "418") - beforeMatched; got != 2 {
+		t.Errorf("Expected both races counted under one route but got %v", got)
+	}
+	if got := count(unmatchedRoute, http.MethodGet, "404") - beforeUnmatched; got != 1 {
+		t.Errorf("Expected the unknown path counted as unmatched but got %v", got)
+	}
+	if got := count(unmatchedRoute, http.MethodGet, "429") - beforeRefused; got != 3 {
+		t.Errorf("Expected the refused paths counted as unmatched but got %v", got)
+	}
+	if got := count(unmatchedRoute, otherMethod, "429") - beforeOther; got != 1 {
+		t.Errorf("Expected the made up method counted as %s but got %v", otherMethod, got)
+	}
+	for _, route := range []string{"/wp-login.php", "/x/abc1"} {
+		for _, code := range []string{"404", "429"} {
+			if got := count(route, http.MethodGet, code); got != 0 {
+				t.Errorf("Expected no series for %s but got %v", route, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.neds.sh/matty/entain/api/proto/racing"
+	"git.neds.sh/matty/entain/api/proto/sports"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// nextToGoDefaultLimit is the number of items returned when no limit is given.
+	nextToGoDefaultLimit = 10
+	// nextToGoMaxLimit caps the limit a client may ask for.
+	nextToGoMaxLimit = 100
+	// sportsCategory is the category of every sport event. Races are categorised by race type.
+	sportsCategory = "sports"
+	// unknownCategory is the category of races listed without their meeting, whose race type is
+	// not known.
+	unknownCategory = "unknown"
+)
+
+// nextToGoCategories are the categories a next to go request can be filtered by.
+var nextToGoCategories = map[string]bool{
+	strings.ToLower(racing.RaceType_THOROUGHBRED.String()): true,
+	strings.ToLower(racing.RaceType_GREYHOUND.String()):    true,
+	strings.ToLower(racing.RaceType_HARNESS.String()):      true,
+	sportsCategory: true,
+}
+
+// nextToGo merges the races and sport events about to start, asking both backends at once.
+type nextToGo struct {
+	racing  racing.RacingClient
+	sports  sports.SportsClient
+	timeout time.Duration
+}
+
+// nextToGoItem is a race or a sport event in a next to go response.
+type nextToGoItem struct {
+	category string
+	start    time.Time
+	race     *racing.Race
+	event    *sports.Event
+}
+
+// nextToGoResponse lists the items ordered by advertised start time. Warnings name the backends
+// that failed or timed out, whose items are missing.
+type nextToGoResponse struct {
+	Items    []nextToGoItem `json:"items"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// registerNextToGo serves GET /v1/next-to-go, e.g. ?limit=5&category=greyhound&category=sports.
+func registerNextToGo(ctx context.Context, mux *runtime.ServeMux, routes *routeTable, racingEndpoint, sportsEndpoint string, timeout time.Duration, opts []grpc.DialOption) error {
+	racingConn, err := dialBackend(ctx, racingEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	sportsConn, err := dialBackend(ctx, sportsEndpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	n := &nextToGo{
+		racing:  racing.NewRacingClient(racingConn),
+		sports:  sports.NewSportsClient(sportsConn),
+		timeout: timeout,
+	}
+
+	return routes.handlePath(mux, http.MethodGet, "/v1/next-to-go", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		limit, categories, err := parseNextToGo(r)
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+			return
+		}
+
+		resp, err := n.list(r.Context(), limit, categories)
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+		}
+	})
+}
+
+// parseNextToGo reads the limit and the categories to include from the query string. No
+// category means every category.
+func parseNextToGo(r *http.Request) (int, map[string]bool, error) {
+	limit := nextToGoDefaultLimit
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > nextToGoMaxLimit {
+			return 0, nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", nextToGoMaxLimit)
+		}
+	}
+
+	categories := make(map[string]bool)
+	for _, value := range r.URL.Query()["category"] {
+		category := strings.ToLower(value)
+		if !nextToGoCategories[category] {
+			return 0, nil, status.Errorf(codes.InvalidArgument, "unknown category %q", value)
+		}
+
+		categories[category] = true
+	}
+
+	if len(categories) == 0 {
+		categories = nextToGoCategories
+	}
+
+	return limit, categories, nil
+}
+
+// list asks the backends serving the categories for their next items, each within the timeout.
+// A backend that fails only adds a warning, unless no backend answered at all.
+func (n *nextToGo) list(ctx context.Context, limit int, categories map[string]bool) (*nextToGoResponse, error) {
+	type result struct {
+		backend string
+		items   []nextToGoItem
+		err     error
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results []*result
+	)
+
+	query := func(backend string, fetch func(context.Context) ([]nextToGoItem, error)) {
+		res := &result{backend: backend}
+		results = append(results, res)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, n.timeout)
+			defer cancel()
+
+			res.items, res.err = fetch(ctx)
+		}()
+	}
+
+	var raceTypes []racing.RaceType
+	for _, raceType := range []racing.RaceType{racing.RaceType_THOROUGHBRED, racing.RaceType_GREYHOUND, racing.RaceType_HARNESS} {
+		if categories[strings.ToLower(raceType.String())] {
+			raceTypes = append(raceTypes, raceType)
+		}
+	}
+
+	if len(raceTypes) > 0 {
+		query("racing", func(ctx context.Context) ([]nextToGoItem, error) {
+			return n.listRaces(ctx, limit, raceTypes)
+		})
+	}
+
+	if categories[sportsCategory] {
+		query("sports", func(ctx context.Context) ([]nextToGoItem, error) {
+			return n.listEvents(ctx, limit)
+		})
+	}
+
+	wg.Wait()
+
+	resp := &nextToGoResponse{Items: []nextToGoItem{}}
+
+	for _, res := range results {
+		if res.err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("%s: %s", res.backend, status.Convert(res.err).Message()))
+			continue
+		}
+
+		resp.Items = append(resp.Items, res.items...)
+	}
+
+	if len(resp.Warnings) == len(results) {
+		return nil, status.Error(codes.Unavailable, strings.Join(resp.Warnings, "; "))
+	}
+
+	sort.SliceStable(resp.Items, func(i, j int) bool {
+		return resp.Items[i].start.Before(resp.Items[j].start)
+	})
+
+	if len(resp.Items) > limit {
+		resp.Items = resp.Items[:limit]
+	}
+
+	return resp, nil
+}
+
+// listRaces returns the next visible races still open for betting, of the given race types.
+func (n *nextToGo) listRaces(ctx context.Context, limit int, raceTypes []racing.RaceType) ([]nextToGoItem, error) {
+	filter := &racing.ListRacesRequestFilter{
+		Visible:  proto.Bool(true),
+		OrderBy:  racing.OrderBy_ASC.Enum(),
+		Statuses: []racing.Status{racing.Status_OPEN},
+	}
+
+	// Races only know their meeting, so a subset of race types is looked up through the meetings.
+	subset := len(raceTypes) < len(racing.RaceType_name)
+	if subset {
+		meetings, err := n.racing.ListMeetings(ctx, &racing.ListMeetingsRequest{
+			Filter: &racing.ListMeetingsRequestFilter{RaceTypes: raceTypes},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(meetings.Meetings) == 0 {
+			return nil, nil
+		}
+
+		for _, meeting := range meetings.Meetings {
+			filter.MeetingIds = append(filter.MeetingIds, meeting.Id)
+		}
+	}
+
+	resp, err := n.racing.ListRaces(ctx, &racing.ListRacesRequest{
+		Filter:          filter,
+		PageSize:        int32(limit),
+		IncludeMeetings: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]nextToGoItem, 0, len(resp.Races))
+	for _, race := range resp.Races {
+		// A race without its meeting has no race type, so it only goes with every category
+		category := unknownCategory
+		if race.GetMeeting() != nil {
+			category = strings.ToLower(race.GetMeeting().GetRaceType().String())
+		} else if subset {
+			continue
+		}
+
+		items = append(items, nextToGoItem{
+			category: category,
+			start:    race.AdvertisedStartTime.AsTime(),
+			race:     race,
+		})
+	}
+
+	return items, nil
+}
+
+// listEvents returns the next visible sport events yet to start.
+func (n *nextToGo) listEvents(ctx context.Context, limit int) ([]nextToGoItem, error) {
+	resp, err := n.sports.ListEvents(ctx, &sports.ListEventsRequest{
+		Filter: &sports.ListEventsRequestFilter{
+			Visible:    proto.Bool(true),
+			Statuses:   []sports.EventStatus{sports.EventStatus_SCHEDULED},
+			OrderField: sports.EventOrderField_START_TIME.Enum(),
+			OrderBy:    sports.OrderBy_ASC.Enum(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := resp.Events
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	items := make([]nextToGoItem, 0, len(events))
+	for _, event := range events {
+		items = append(items, nextToGoItem{
+			category: sportsCategory,
+			start:    event.AdvertisedStartTime.AsTime(),
+			event:    event,
+		})
+	}
+
+	return items, nil
+}
+
+// MarshalJSON writes the item's category and start time next to the race or sport event, which
+// is marshalled the way the generated routes marshal it.
+func (i nextToGoItem) MarshalJSON() ([]byte, error) {
+	var out struct {
+		Category            string          `json:"category"`
+		AdvertisedStartTime time.Time       `json:"advertisedStartTime"`
+		Race                json.RawMessage `json:"race,omitempty"`
+		SportsEvent         json.RawMessage `json:"sportsEvent,omitempty"`
+	}
+
+	out.Category = i.category
+	out.AdvertisedStartTime = i.start
+
+	var err error
+	if i.race != nil {
+		out.Race, err = protoJSON.Marshal(i.race)
+	} else {
+		out.SportsEvent, err = protoJSON.Marshal(i.event)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(out)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/api/proto/racing"
+	"git.neds.sh/matty/entain/api/proto/sports"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// stubRacing answers ListMeetings and ListRaces from fixed data.
+type stubRacing struct {
+	racing.RacingClient
+	meetings []*racing.Meeting
+	races    []*racing.Race
+	err      error
+}
+
+func (s *stubRacing) ListMeetings(ctx context.Context, in *racing.ListMeetingsRequest, _ ...grpc.CallOption) (*racing.ListMeetingsResponse, error) {
+	var meetings []*racing.Meeting
+	for _, meeting := range s.meetings {
+		for _, raceType := range in.Filter.RaceTypes {
+			if meeting.RaceType == raceType {
+				meetings = append(meetings, meeting)
+			}
+		}
+	}
+
+	return &racing.ListMeetingsResponse{Meetings: meetings}, nil
+}
+
+func (s *stubRacing) ListRaces(ctx context.Context, in *racing.ListRacesRequest, _ ...grpc.CallOption) (*racing.ListRacesResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var races []*racing.Race
+	for _, race := range s.races {
+		if len(in.Filter.MeetingIds) > 0 && race.MeetingId != in.Filter.MeetingIds[0] {
+			continue
+		}
+
+		races = append(races, race)
+	}
+
+	if len(races) > int(in.PageSize) {
+		races = races[:in.PageSize]
+	}
+
+	return &racing.ListRacesResponse{Races: races}, nil
+}
+
+// stubSports answers ListEvents from fixed data, after an optional delay.
+type stubSports struct {
+	sports.SportsClient
+	events []*sports.Event
+	delay  time.Duration
+}
+
+func (s *stubSports) ListEvents(ctx context.Context, in *sports.ListEventsRequest, _ ...grpc.CallOption) (*sports.ListEventsReponse, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	return &sports.ListEventsReponse{Events: s.events}, nil
+}
+
+func TestNextToGo_MergesBackends(t *testing.T) {
+	now := time.Now()
+	at := func(minutes int) *timestamppb.Timestamp {
+		return timestamppb.New(now.Add(time.Duration(minutes) * time.Minute))
+	}
+
+	greyhounds := &racing.Meeting{Id: 1, RaceType: racing.RaceType_GREYHOUND}
+	harness := &racing.Meeting{Id: 2, RaceType: racing.RaceType_HARNESS}
+
+	racingClient := &stubRacing{
+		meetings: []*racing.Meeting{greyhounds, harness},
+		races: []*racing.Race{
+			{Id: 1, MeetingId: 1, Meeting: greyhounds, AdvertisedStartTime: at(2)},
+			{Id: 2, MeetingId: 2, Meeting: harness, AdvertisedStartTime: at(5)},
+			{Id: 3, MeetingId: 1, Meeting: greyhounds, AdvertisedStartTime: at(9)},
+		},
+	}
+	sportsClient := &stubSports{
+		events: []*sports.Event{
+			{Id: 1, AdvertisedStartTime: at(1)},
+			{Id: 2, AdvertisedStartTime: at(6)},
+		},
+	}
+
+	n := &nextToGo{racing: racingClient, sports: sportsClient, timeout: 100 * time.Millisecond}
+
+	// ids names each item by its category and the id of its race or event
+	ids := func(resp *nextToGoResponse) []string {
+		var got []string
+		for _, item := range resp.Items {
+			id := item.event.GetId()
+			if item.race != nil {
+				id = item.race.Id
+			}
+
+			got = append(got, fmt.Sprintf("%s:%d", item.category, id))
+		}
+
+		return got
+	}
+
+	ctx := context.Background()
+
+	// Both backends are merged by start time and cut to the limit
+	resp, err := n.list(ctx, 4, nextToGoCategories)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"sports:1", "greyhound:1", "harness:2", "sports:2"}
+	if !reflect.DeepEqual(ids(resp), expected) || len(resp.Warnings) != 0 {
+		t.Errorf("Expected %v without warnings but got %v with %v", expected, ids(resp), resp.Warnings)
+	}
+
+	// Categories limit the backends and race types asked
+	resp, err = n.list(ctx, 10, map[string]bool{"greyhound": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"greyhound:1", "greyhound:3"}
+	if !reflect.DeepEqual(ids(resp), expected) {
+		t.Errorf("Expected %v but got %v", expected, ids(resp))
+	}
+
+	// A slow backend is left out with a warning
+	sportsClient.delay = time.Second
+	resp, err = n.list(ctx, 10, nextToGoCategories)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Items) != 3 || len(resp.Warnings) != 1 {
+		t.Errorf("Expected the 3 races and a warning but got %v with %v", ids(resp), resp.Warnings)
+	}
+
+	// The call only fails when no backend answers
+	racingClient.err = status.Error(codes.Unavailable, "racing is down")
+	_, err = n.list(ctx, 10, nextToGoCategories)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected error code %v but got %v", codes.Unavailable, status.Code(err))
+	}
+}
+
+func TestNextToGo_RaceWithoutMeeting(t *testing.T) {
+	now := time.Now()
+	at := func(minutes int) *timestamppb.Timestamp {
+		return timestamppb.New(now.Add(time.Duration(minutes) * time.Minute))
+	}
+
+	thoroughbreds := &racing.Meeting{Id: 1, RaceType: racing.RaceType_THOROUGHBRED}
+
+	// Race 2 is listed without its meeting, as if it could not be found
+	racingClient := &stubRacing{
+		meetings: []*racing.Meeting{thoroughbreds},
+		races: []*racing.Race{
+			{Id: 1, MeetingId: 1, Meeting: thoroughbreds, AdvertisedStartTime: at(2)},
+			{Id: 2, MeetingId: 1, AdvertisedStartTime: at(3)},
+		},
+	}
+
+	n := &nextToGo{racing: racingClient, sports: &stubSports{}, timeout: 100 * time.Millisecond}
+
+	ctx := context.Background()
+
+	// It is not taken for a thoroughbred race
+	resp, err := n.list(ctx, 10, map[string]bool{"thoroughbred": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Items) != 1 || resp.Items[0].race.Id != 1 {
+		t.Errorf("Expected only race 1 but got %v", resp.Items)
+	}
+
+	// With every category it is listed as unknown
+	resp, err = n.list(ctx, 10, nextToGoCategories)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var categories []string
+	for _, item := range resp.Items {
+		categories = append(categories, item.category)
+	}
+
+	expected := []string{"thoroughbred", "unknown"}
+	if !reflect.DeepEqual(categories, expected) {
+		t.Errorf("Expected categories %v but got %v", expected, categories)
+	}
+}
+
+func TestNextToGo_ParseAndMarshal(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=101", "limit=ten", "category=darts"} {
+		_, _, err := parseNextToGo(httptest.NewRequest("GET", "/v1/next-to-go?"+query, nil))
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected error code %v for %q but got %v", codes.InvalidArgument, query, status.Code(err))
+		}
+	}
+
+	limit, categories, err := parseNextToGo(httptest.NewRequest("GET", "/v1/next-to-go?limit=3&category=Greyhound&category=sports", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit != 3 || !reflect.DeepEqual(categories, map[string]bool{"greyhound": true, "sports": true}) {
+		t.Errorf("Expected limit 3 for greyhound and sports but got %d for %v", limit, categories)
+	}
+
+	start := time.Date(2030, 4, 5, 6, 0, 0, 0, time.UTC)
+	data, err := json.Marshal(nextToGoItem{category: "sports", start: start, event: &sports.Event{Id: 7, AdvertisedStartTime: timestamppb.New(start)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"category":"sports","advertisedStartTime":"2030-04-05T06:00:00Z","sportsEvent":{"id":"7","name":"","cityAddress":"","numOfParticipants":"0","advertisedStartTime":"2030-04-05T06:00:00Z","sportId":"0","competitionId":"0","visible":false,"status":"SCHEDULED","score":null}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s but got %s", expected, data)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -26,6 +27,38 @@ type RacingClient interface {
 	ListRaces(ctx context.Context, in *ListRacesRequest, opts ...grpc.CallOption) (*ListRacesResponse, error)
 	// Get a single race by its id
 	GetRace(ctx context.Context, in *GetRaceRequest, opts ...grpc.CallOption) (*Race, error)
+	// UpdateRaceStatus moves a race to a new lifecycle status, rejecting illegal transitions.
+	// INTERIM and FINAL are only reached through SetResult. Abandoning a race discards its results.
+	UpdateRaceStatus(ctx context.Context, in *UpdateRaceStatusRequest, opts ...grpc.CallOption) (*Race, error)
+	// ListMeetings returns a list of all meetings.
+	ListMeetings(ctx context.Context, in *ListMeetingsRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error)
+	// Get a single meeting by its id
+	GetMeeting(ctx context.Context, in *GetMeetingRequest, opts ...grpc.CallOption) (*Meeting, error)
+	// ListRunners returns the runners of a race, i.e. its race card.
+	ListRunners(ctx context.Context, in *ListRunnersRequest, opts ...grpc.CallOption) (*ListRunnersResponse, error)
+	// GetPrices returns the current fixed-odds price of each runner in a race.
+	GetPrices(ctx context.Context, in *GetPricesRequest, opts ...grpc.CallOption) (*GetPricesResponse, error)
+	// ListPriceHistory returns every price a runner has been offered at, oldest first.
+	ListPriceHistory(ctx context.Context, in *ListPriceHistoryRequest, opts ...grpc.CallOption) (*ListPriceHistoryResponse, error)
+	// UpdatePrice offers a runner at a new price. Prices are locked once the race leaves OPEN.
+	UpdatePrice(ctx context.Context, in *UpdatePriceRequest, opts ...grpc.CallOption) (*Price, error)
+	// SetResult records the placings and dividends of a race and moves it to INTERIM or FINAL.
+	SetResult(ctx context.Context, in *SetResultRequest, opts ...grpc.CallOption) (*Race, error)
+	// ListResults returns the placings of resulted races.
+	ListResults(ctx context.Context, in *ListResultsRequest, opts ...grpc.CallOption) (*ListResultsResponse, error)
+	// WatchRaces streams changes to the races matching a filter. The gateway serves it as
+	// Server-Sent Events on GET /v1/watch-races rather than through a generated binding.
+	WatchRaces(ctx context.Context, in *WatchRacesRequest, opts ...grpc.CallOption) (Racing_WatchRacesClient, error)
+	// WatchChanges streams a signal each time races change, without the races themselves, for
+	// callers that only need to know when to read them again. A first signal is sent once watching.
+	// It is only served over gRPC, the gateway uses it to drop cached responses early.
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Racing_WatchChangesClient, error)
+	// CreateRace adds a race. Admin only.
+	CreateRace(ctx context.Context, in *CreateRaceRequest, opts ...grpc.CallOption) (*Race, error)
+	// UpdateRace changes the fields of a race named in update_mask. Admin only.
+	UpdateRace(ctx context.Context, in *UpdateRaceRequest, opts ...grpc.CallOption) (*Race, error)
+	// DeleteRace removes a race along with its runners, prices and results. Admin only.
+	DeleteRace(ctx context.Context, in *DeleteRaceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type racingClient struct {
@@ -54,6 +87,178 @@ func (c *racingClient) GetRace(ctx context.Context, in *GetRaceRequest, opts ...
 	return out, nil
 }
 
+func (c *racingClient) UpdateRaceStatus(ctx context.Context, in *UpdateRaceStatusRequest, opts ...grpc.CallOption) (*Race, error) {
+	out := new(Race)
+	err := c.cc.Invoke(ctx, "/racing.Racing/UpdateRaceStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) ListMeetings(ctx context.Context, in *ListMeetingsRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error) {
+	out := new(ListMeetingsResponse)
+	err := c.cc.Invoke(ctx, "/racing.Racing/ListMeetings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) GetMeeting(ctx context.Context, in *GetMeetingRequest, opts ...grpc.CallOption) (*Meeting, error) {
+	out := new(Meeting)
+	err := c.cc.Invoke(ctx, "/racing.Racing/GetMeeting", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) ListRunners(ctx context.Context, in *ListRunnersRequest, opts ...grpc.CallOption) (*ListRunnersResponse, error) {
+	out := new(ListRunnersResponse)
+	err := c.cc.Invoke(ctx, "/racing.Racing/ListRunners", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) GetPrices(ctx context.Context, in *GetPricesRequest, opts ...grpc.CallOption) (*GetPricesResponse, error) {
+	out := new(GetPricesResponse)
+	err := c.cc.Invoke(ctx, "/racing.Racing/GetPrices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) ListPriceHistory(ctx context.Context, in *ListPriceHistoryRequest, opts ...grpc.CallOption) (*ListPriceHistoryResponse, error) {
+	out := new(ListPriceHistoryResponse)
+	err := c.cc.Invoke(ctx, "/racing.Racing/ListPriceHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) UpdatePrice(ctx context.Context, in *UpdatePriceRequest, opts ...grpc.CallOption) (*Price, error) {
+	out := new(Price)
+	err := c.cc.Invoke(ctx, "/racing.Racing/UpdatePrice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) SetResult(ctx context.Context, in *SetResultRequest, opts ...grpc.CallOption) (*Race, error) {
+	out := new(Race)
+	err := c.cc.Invoke(ctx, "/racing.Racing/SetResult", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) ListResults(ctx context.Context, in *ListResultsRequest, opts ...grpc.CallOption) (*ListResultsResponse, error) {
+	out := new(ListResultsResponse)
+	err := c.cc.Invoke(ctx, "/racing.Racing/ListResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) WatchRaces(ctx context.Context, in *WatchRacesRequest, opts ...grpc.CallOption) (Racing_WatchRacesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Racing_ServiceDesc.Streams[0], "/racing.Racing/WatchRaces", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &racingWatchRacesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Racing_WatchRacesClient interface {
+	Recv() (*RaceEvent, error)
+	grpc.ClientStream
+}
+
+type racingWatchRacesClient struct {
+	grpc.ClientStream
+}
+
+func (x *racingWatchRacesClient) Recv() (*RaceEvent, error) {
+	m := new(RaceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *racingClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Racing_WatchChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Racing_ServiceDesc.Streams[1], "/racing.Racing/WatchChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &racingWatchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Racing_WatchChangesClient interface {
+	Recv() (*emptypb.Empty, error)
+	grpc.ClientStream
+}
+
+type racingWatchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *racingWatchChangesClient) Recv() (*emptypb.Empty, error) {
+	m := new(emptypb.Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *racingClient) CreateRace(ctx context.Context, in *CreateRaceRequest, opts ...grpc.CallOption) (*Race, error) {
+	out := new(Race)
+	err := c.cc.Invoke(ctx, "/racing.Racing/CreateRace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) UpdateRace(ctx context.Context, in *UpdateRaceRequest, opts ...grpc.CallOption) (*Race, error) {
+	out := new(Race)
+	err := c.cc.Invoke(ctx, "/racing.Racing/UpdateRace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *racingClient) DeleteRace(ctx context.Context, in *DeleteRaceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/racing.Racing/DeleteRace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RacingServer is the server API for Racing service.
 // All implementations must embed UnimplementedRacingServer
 // for forward compatibility
@@ -62,6 +267,38 @@ type RacingServer interface {
 	ListRaces(context.Context, *ListRacesRequest) (*ListRacesResponse, error)
 	// Get a single race by its id
 	GetRace(context.Context, *GetRaceRequest) (*Race, error)
+	// UpdateRaceStatus moves a race to a new lifecycle status, rejecting illegal transitions.
+	// INTERIM and FINAL are only reached through SetResult. Abandoning a race discards its results.
+	UpdateRaceStatus(context.Context, *UpdateRaceStatusRequest) (*Race, error)
+	// ListMeetings returns a list of all meetings.
+	ListMeetings(context.Context, *ListMeetingsRequest) (*ListMeetingsResponse, error)
+	// Get a single meeting by its id
+	GetMeeting(context.Context, *GetMeetingRequest) (*Meeting, error)
+	// ListRunners returns the runners of a race, i.e. its race card.
+	ListRunners(context.Context, *ListRunnersRequest) (*ListRunnersResponse, error)
+	// GetPrices returns the current fixed-odds price of each runner in a race.
+	GetPrices(context.Context, *GetPricesRequest) (*GetPricesResponse, error)
+	// ListPriceHistory returns every price a runner has been offered at, oldest first.
+	ListPriceHistory(context.Context, *ListPriceHistoryRequest) (*ListPriceHistoryResponse, error)
+	// UpdatePrice offers a runner at a new price. Prices are locked once the race leaves OPEN.
+	UpdatePrice(context.Context, *UpdatePriceRequest) (*Price, error)
+	// SetResult records the placings and dividends of a race and moves it to INTERIM or FINAL.
+	SetResult(context.Context, *SetResultRequest) (*Race, error)
+	// ListResults returns the placings of resulted races.
+	ListResults(context.Context, *ListResultsRequest) (*ListResultsResponse, error)
+	// WatchRaces streams changes to the races matching a filter. The gateway serves it as
+	// Server-Sent Events on GET /v1/watch-races rather than through a generated binding.
+	WatchRaces(*WatchRacesRequest, Racing_WatchRacesServer) error
+	// WatchChanges streams a signal each time races change, without the races themselves, for
+	// callers that only need to know when to read them again. A first signal is sent once watching.
+	// It is only served over gRPC, the gateway uses it to drop cached responses early.
+	WatchChanges(*WatchChangesRequest, Racing_WatchChangesServer) error
+	// CreateRace adds a race. Admin only.
+	CreateRace(context.Context, *CreateRaceRequest) (*Race, error)
+	// UpdateRace changes the fields of a race named in update_mask. Admin only.
+	UpdateRace(context.Context, *UpdateRaceRequest) (*Race, error)
+	// DeleteRace removes a race along with its runners, prices and results. Admin only.
+	DeleteRace(context.Context, *DeleteRaceRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedRacingServer()
 }
 
@@ -75,6 +312,48 @@ func (UnimplementedRacingServer) ListRaces(context.Context, *ListRacesRequest) (
 func (UnimplementedRacingServer) GetRace(context.Context, *GetRaceRequest) (*Race, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRace not implemented")
 }
+func (UnimplementedRacingServer) UpdateRaceStatus(context.Context, *UpdateRaceStatusRequest) (*Race, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRaceStatus not implemented")
+}
+func (UnimplementedRacingServer) ListMeetings(context.Context, *ListMeetingsRequest) (*ListMeetingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMeetings not implemented")
+}
+func (UnimplementedRacingServer) GetMeeting(context.Context, *GetMeetingRequest) (*Meeting, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMeeting not implemented")
+}
+func (UnimplementedRacingServer) ListRunners(context.Context, *ListRunnersRequest) (*ListRunnersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRunners not implemented")
+}
+func (UnimplementedRacingServer) GetPrices(context.Context, *GetPricesRequest) (*GetPricesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrices not implemented")
+}
+func (UnimplementedRacingServer) ListPriceHistory(context.Context, *ListPriceHistoryRequest) (*ListPriceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPriceHistory not implemented")
+}
+func (UnimplementedRacingServer) UpdatePrice(context.Context, *UpdatePriceRequest) (*Price, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePrice not implemented")
+}
+func (UnimplementedRacingServer) SetResult(context.Context, *SetResultRequest) (*Race, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetResult not implemented")
+}
+func (UnimplementedRacingServer) ListResults(context.Context, *ListResultsRequest) (*ListResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListResults not implemented")
+}
+func (UnimplementedRacingServer) WatchRaces(*WatchRacesRequest, Racing_WatchRacesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRaces not implemented")
+}
+func (UnimplementedRacingServer) WatchChanges(*WatchChangesRequest, Racing_WatchChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedRacingServer) CreateRace(context.Context, *CreateRaceRequest) (*Race, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRace not implemented")
+}
+func (UnimplementedRacingServer) UpdateRace(context.Context, *UpdateRaceRequest) (*Race, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRace not implemented")
+}
+func (UnimplementedRacingServer) DeleteRace(context.Context, *DeleteRaceRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRace not implemented")
+}
 func (UnimplementedRacingServer) mustEmbedUnimplementedRacingServer() {}
 
 // UnsafeRacingServer may be embedded to opt out of forward compatibility for this service.
@@ -124,6 +403,264 @@ func _Racing_GetRace_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Racing_UpdateRaceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRaceStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).UpdateRaceStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/UpdateRaceStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).UpdateRaceStatus(ctx, req.(*UpdateRaceStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_ListMeetings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMeetingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).ListMeetings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/ListMeetings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).ListMeetings(ctx, req.(*ListMeetingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_GetMeeting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMeetingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).GetMeeting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/GetMeeting",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).GetMeeting(ctx, req.(*GetMeetingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_ListRunners_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRunnersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).ListRunners(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/ListRunners",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).ListRunners(ctx, req.(*ListRunnersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_GetPrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPricesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).GetPrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/GetPrices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).GetPrices(ctx, req.(*GetPricesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_ListPriceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPriceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).ListPriceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/ListPriceHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).ListPriceHistory(ctx, req.(*ListPriceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_UpdatePrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).UpdatePrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/UpdatePrice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).UpdatePrice(ctx, req.(*UpdatePriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_SetResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).SetResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/SetResult",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).SetResult(ctx, req.(*SetResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_ListResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).ListResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/ListResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).ListResults(ctx, req.(*ListResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_WatchRaces_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRacesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RacingServer).WatchRaces(m, &racingWatchRacesServer{stream})
+}
+
+type Racing_WatchRacesServer interface {
+	Send(*RaceEvent) error
+	grpc.ServerStream
+}
+
+type racingWatchRacesServer struct {
+	grpc.ServerStream
+}
+
+func (x *racingWatchRacesServer) Send(m *RaceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Racing_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RacingServer).WatchChanges(m, &racingWatchChangesServer{stream})
+}
+
+type Racing_WatchChangesServer interface {
+	Send(*emptypb.Empty) error
+	grpc.ServerStream
+}
+
+type racingWatchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *racingWatchChangesServer) Send(m *emptypb.Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Racing_CreateRace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).CreateRace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/CreateRace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).CreateRace(ctx, req.(*CreateRaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_UpdateRace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).UpdateRace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/UpdateRace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).UpdateRace(ctx, req.(*UpdateRaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Racing_DeleteRace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RacingServer).DeleteRace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/racing.Racing/DeleteRace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RacingServer).DeleteRace(ctx, req.(*DeleteRaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Racing_ServiceDesc is the grpc.ServiceDesc for Racing service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -139,7 +676,66 @@ var Racing_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetRace",
 			Handler:    _Racing_GetRace_Handler,
 		},
+		{
+			MethodName: "UpdateRaceStatus",
+			Handler:    _Racing_UpdateRaceStatus_Handler,
+		},
+		{
+			MethodName: "ListMeetings",
+			Handler:    _Racing_ListMeetings_Handler,
+		},
+		{
+			MethodName: "GetMeeting",
+			Handler:    _Racing_GetMeeting_Handler,
+		},
+		{
+			MethodName: "ListRunners",
+			Handler:    _Racing_ListRunners_Handler,
+		},
+		{
+			MethodName: "GetPrices",
+			Handler:    _Racing_GetPrices_Handler,
+		},
+		{
+			MethodName: "ListPriceHistory",
+			Handler:    _Racing_ListPriceHistory_Handler,
+		},
+		{
+			MethodName: "UpdatePrice",
+			Handler:    _Racing_UpdatePrice_Handler,
+		},
+		{
+			MethodName: "SetResult",
+			Handler:    _Racing_SetResult_Handler,
+		},
+		{
+			MethodName: "ListResults",
+			Handler:    _Racing_ListResults_Handler,
+		},
+		{
+			MethodName: "CreateRace",
+			Handler:    _Racing_CreateRace_Handler,
+		},
+		{
+			MethodName: "UpdateRace",
+			Handler:    _Racing_UpdateRace_Handler,
+		},
+		{
+			MethodName: "DeleteRace",
+			Handler:    _Racing_DeleteRace_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRaces",
+			Handler:       _Racing_WatchRaces_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _Racing_WatchChanges_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "racing/racing.proto",
 }
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// apiKeyHeader identifies a client holding a known API key. Other clients are told apart by
+	// their address.
+	apiKeyHeader = "X-API-Key"
+	// overflowClient is the client of the requests of new clients once the buckets are capped,
+	// who share a bucket per rule until the others are swept.
+	overflowClient = "overflow"
+	// defaultRoute is the route of the rule applied to requests no other rule matches.
+	defaultRoute = "*"
+	// rateLimitSweepInterval is how often buckets left untouched long enough to be full again
+	// are dropped.
+	rateLimitSweepInterval = time.Minute
+)
+
+var (
+	rateLimitRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_rate_limit_requests_total",
+		Help: "Requests checked against the rate limits, by route rule and whether they were allowed or limited.",
+	}, []string{"route", "result"})
+
+	rateLimitQuotaUsed = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "api_rate_limit_quota_used_ratio",
+		Help:    "Share of its burst a client had used once its request was counted, by route rule.",
+		Buckets: []float64{0.1, 0.25, 0.5, 0.75, 0.9, 1},
+	}, []string{"route"})
+
+	rateLimitClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "api_rate_limit_clients",
+		Help: "Buckets currently kept in memory, one per client and route rule.",
+	})
+)
+
+// rateLimit is a token bucket refilled at rate requests per second, holding up to burst requests.
+type rateLimit struct {
+	rate  rate.Limit
+	burst int
+}
+
+// rateLimitRule applies a limit to the requests whose path matches route. A route is a path
+// where a segment may be a {name} or * wildcard, e.g. /v1/race/{id}, or * for every path.
+type rateLimitRule struct {
+	route string
+	limit rateLimit
+}
+
+// matches reports whether the rule applies to the path.
+func (r rateLimitRule) matches(path string) bool {
+	if r.route == defaultRoute {
+		return true
+	}
+
+	routeSegments := strings.Split(strings.Trim(r.route, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(routeSegments) != len(pathSegments) {
+		return false
+	}
+
+	for i, segment := range routeSegments {
+		if segment != "*" && !strings.HasPrefix(segment, "{") && segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rateLimitRules is a flag listing rules as ROUTE=RATE:BURST, e.g. /v1/list-races=5:10. The
+// flag may be repeated, and the first rule matching a request applies.
+type rateLimitRules []rateLimitRule
+
+func (r *rateLimitRules) String() string {
+	var rules []string
+	for _, rule := range *r {
+		rules = append(rules, fmt.Sprintf("%s=%v:%d", rule.route, float64(rule.limit.rate), rule.limit.burst))
+	}
+
+	return strings.Join(rules, ",")
+}
+
+func (r *rateLimitRules) Set(value string) error {
+	routeAndLimit := strings.SplitN(value, "=", 2)
+	if len(routeAndLimit) != 2 || routeAndLimit[0] == "" {
+		return fmt.Errorf("rate limit %q is not ROUTE=RATE:BURST", value)
+	}
+
+	rateAndBurst := strings.SplitN(routeAndLimit[1], ":", 2)
+	if len(rateAndBurst) != 2 {
+		return fmt.Errorf("rate limit %q is not ROUTE=RATE:BURST", value)
+	}
+
+	perSecond, err := strconv.ParseFloat(rateAndBurst[0], 64)
+	if err != nil || perSecond <= 0 {
+		return fmt.Errorf("rate of %q must be a positive number of requests per second", value)
+	}
+
+	burst, err := strconv.Atoi(rateAndBurst[1])
+	if err != nil || burst < 1 {
+		return fmt.Errorf("burst of %q must be at least 1", value)
+	}
+
+	*r = append(*r, rateLimitRule{route: routeAndLimit[0], limit: rateLimit{rate: rate.Limit(perSecond), burst: burst}})
+
+	return nil
+}
+
+// bucketKey names the bucket of a client for a rule.
+type bucketKey struct {
+	client string
+	route  string
+}
+
+// rateLimiter keeps a token bucket per client and rule, in memory.
+type rateLimiter struct {
+	rules        []rateLimitRule
+	forwardedFor bool
+	apiKeys      map[string]bool
+	maxBuckets   int
+	now          func() time.Time
+	mu           sync.Mutex
+	buckets      map[bucketKey]*rate.Limiter
+	lastSweep    time.Time
+}
+
+// newRateLimiter applies the rules in order, then fallback to the requests matching none. Only
+// clients sending one of apiKeys are told apart by it, so made up keys cannot get fresh buckets.
+// With forwardedFor, other clients are told apart by the first X-Forwarded-For address, which
+// only a trusted proxy in front of the gateway should set. At most maxBuckets buckets are kept,
+// besides those of the overflow client.
+func newRateLimiter(rules []rateLimitRule, fallback rateLimit, forwardedFor bool, apiKeys map[string]bool, maxBuckets int) *rateLimiter {
+	return &rateLimiter{
+		rules:        append(append([]rateLimitRule(nil), rules...), rateLimitRule{route: defaultRoute, limit: fallback}),
+		forwardedFor: forwardedFor,
+		apiKeys:      apiKeys,
+		maxBuckets:   maxBuckets,
+		now:          time.Now,
+		buckets:      make(map[bucketKey]*rate.Limiter),
+	}
+}
+
+// loadAPIKeys reads the API keys of a file, one per line. Blank lines and lines starting with #
+// are skipped.
+func loadAPIKeys(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	keys := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+
+		keys[key] = true
+	}
+
+	return keys, scanner.Err()
+}
+
+// client names the caller of a request by its API key when it is a known one, or else by its
+// address.
+func (l *rateLimiter) client(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); l.apiKeys[key] {
+		return "key:" + key
+	}
+
+	if l.forwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return "ip:" + strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// allow takes a token from the client's bucket for the path. It returns the rule applied, the
+// tokens left and, when no token was left, how long until the next one.
+func (l *rateLimiter) allow(client, path string) (rateLimitRule, int, time.Duration) {
+	var rule rateLimitRule
+	for _, rule = range l.rules {
+		if rule.matches(path) {
+			break
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	key := bucketKey{client: client, route: rule.route}
+	bucket, ok := l.buckets[key]
+	if !ok && len(l.buckets) >= l.maxBuckets {
+		key.client = overflowClient
+		bucket, ok = l.buckets[key]
+	}
+	if !ok {
+		bucket = rate.NewLimiter(rule.limit.rate, rule.limit.burst)
+		l.buckets[key] = bucket
+		rateLimitClients.Set(float64(len(l.buckets)))
+	}
+
+	reservation := bucket.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		// The token is only taken when the request is allowed
+		reservation.CancelAt(now)
+		return rule, 0, delay
+	}
+
+	return rule, int(bucket.TokensAt(now)), 0
+}
+
+// sweep drops the buckets that are full again. A new bucket would be the same, so no quota is
+// lost, and clients that went away are forgotten.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.TokensAt(now) >= float64(bucket.Burst()) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+	rateLimitClients.Set(float64(len(l.buckets)))
+}
+
+// handler checks each request against the client's bucket before passing it on to next. Every
+// response tells the client its limit and the requests it has left; a limited request gets
+// 429 Too Many Requests, with Retry-After set to the seconds until it may try again.
+func (l *rateLimiter) handler(mux *runtime.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule, remaining, retryAfter := l.allow(l.client(r), r.URL.Path)
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rule.limit.burst))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if retryAfter > 0 {
+			rateLimitRequests.WithLabelValues(rule.route, "limited").Inc()
+			rateLimitQuotaUsed.WithLabelValues(rule.route).Observe(1)
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, status.Errorf(codes.ResourceExhausted, "rate limit of %d requests exceeded, retry in %s", rule.limit.burst, retryAfter.Round(time.Millisecond)))
+			return
+		}
+
+		rateLimitRequests.WithLabelValues(rule.route, "allowed").Inc()
+		rateLimitQuotaUsed.WithLabelValues(rule.route).Observe(1 - float64(remaining)/float64(rule.limit.burst))
+
+		next.ServeHTTP(w, r)
+	})
+}
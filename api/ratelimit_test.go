@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitRules_Set(t *testing.T) {
+	var rules rateLimitRules
+	for _, value := range []string{"/v1/list-races=5:10", "/v1/race/{id}=0.5:1"} {
+		if err := rules.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if rules.String() != "/v1/list-races=5:10,/v1/race/{id}=0.5:1" {
+		t.Errorf("Expected both rules but got %s", rules.String())
+	}
+
+	for _, value := range []string{"", "/v1/list-races", "=5:10", "/v1/list-races=5", "/v1/list-races=0:10", "/v1/list-races=five:10", "/v1/list-races=5:0"} {
+		if err := rules.Set(value); err == nil {
+			t.Errorf("Expected %q to be refused", value)
+		}
+	}
+
+	rule := rateLimitRule{route: "/v1/race/{id}/*"}
+	for path, expected := range map[string]bool{
+		"/v1/race/5/runners": true,
+		"/v1/race/5/prices/": true,
+		"/v1/race/5":         false,
+		"/v1/meeting/5/runs": false,
+	} {
+		if rule.matches(path) != expected {
+			t.Errorf("Expected %s matching %s to be %v", rule.route, path, expected)
+		}
+	}
+}
+
+func TestRateLimiter_Buckets(t *testing.T) {
+	now := time.Date(2030, 4, 5, 6, 0, 0, 0, time.UTC)
+
+	limiter := newRateLimiter([]rateLimitRule{{route: "/v1/list-races", limit: rateLimit{rate: 1, burst: 2}}}, rateLimit{rate: rate.Limit(10), burst: 5}, false, nil, 100)
+	limiter.now = func() time.Time { return now }
+
+	// The burst is allowed, then the client waits for the bucket to refill
+	for i, expected := range []int{1, 0} {
+		if rule, remaining, retryAfter := limiter.allow("ip:10.0.0.1", "/v1/list-races"); rule.route != "/v1/list-races" || remaining != expected || retryAfter != 0 {
+			t.Errorf("Expected request %d to be allowed with %d left but got %s, %d left, retry after %s", i+1, expected, rule.route, remaining, retryAfter)
+		}
+	}
+
+	if _, _, retryAfter := limiter.allow("ip:10.0.0.1", "/v1/list-races"); retryAfter != time.Second {
+		t.Errorf("Expected to retry after 1s but got %s", retryAfter)
+	}
+
+	// Other clients and other routes have buckets of their own
+	if _, _, retryAfter := limiter.allow("ip:10.0.0.2", "/v1/list-races"); retryAfter != 0 {
+		t.Errorf("Expected another client to be allowed but it has to retry after %s", retryAfter)
+	}
+
+	if rule, remaining, _ := limiter.allow("ip:10.0.0.1", "/v1/race/1"); rule.route != defaultRoute || remaining != 4 {
+		t.Errorf("Expected the default limit with 4 left but got %s with %d left", rule.route, remaining)
+	}
+
+	// Limited requests do not take a token, so the client may go on once the bucket refilled
+	now = now.Add(time.Second)
+	if _, _, retryAfter := limiter.allow("ip:10.0.0.1", "/v1/list-races"); retryAfter != 0 {
+		t.Errorf("Expected the refilled bucket to allow the request but it has to retry after %s", retryAfter)
+	}
+
+	// Buckets full again are forgotten
+	now = now.Add(rateLimitSweepInterval)
+	limiter.allow("ip:10.0.0.3", "/v1/list-races")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected only the new bucket to be kept but got %v", limiter.buckets)
+	}
+}
+
+func TestRateLimiter_Handler(t *testing.T) {
+	limiter := newRateLimiter(nil, rateLimit{rate: rate.Limit(0.5), burst: 1}, true, map[string]bool{"partner-1": true}, 100)
+
+	handler := limiter.handler(runtime.NewServeMux(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/list-races", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := serve("", "")
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "1" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("Expected the first request to be allowed with no request left but got %d with %v", w.Code, w.Header())
+	}
+
+	w = serve("", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected 429 with Retry-After 2 but got %d with %v", w.Code, w.Header())
+	}
+
+	// Known API keys and forwarded addresses name other clients
+	if w = serve(apiKeyHeader, "partner-1"); w.Code != http.StatusOK {
+		t.Errorf("Expected the API key to have its own bucket but got %d", w.Code)
+	}
+	if w = serve(apiKeyHeader, "partner-2"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the unknown API key to share the address's bucket but got %d", w.Code)
+	}
+	if w = serve("X-Forwarded-For", "203.0.113.7, 10.0.0.1"); w.Code != http.StatusOK {
+		t.Errorf("Expected the forwarded address to have its own bucket but got %d", w.Code)
+	}
+	if w = serve("X-Forwarded-For", "203.0.113.7"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the forwarded address to be limited but got %d", w.Code)
+	}
+}
+
+func TestRateLimiter_RotatedAPIKeys(t *testing.T) {
+	limiter := newRateLimiter(nil, rateLimit{rate: rate.Limit(0.5), burst: 1}, false, nil, 100)
+
+	handler := limiter.handler(runtime.NewServeMux(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// A client making up a new key for each request is still limited by its address
+	for i := 0; i < 50; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/v1/list-races", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set(apiKeyHeader, fmt.Sprintf("made-up-%d", i))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if expected := http.StatusTooManyRequests; i > 0 && w.Code != expected {
+			t.Fatalf("Expected request %d to be limited with %d but got %d", i+1, expected, w.Code)
+		}
+	}
+
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected a single bucket but got %d", len(limiter.buckets))
+	}
+}
+
+func TestRateLimiter_MaxBuckets(t *testing.T) {
+	limiter := newRateLimiter(nil, rateLimit{rate: rate.Limit(0.5), burst: 1}, false, nil, 2)
+
+	// Once the buckets are capped, new clients share a bucket
+	for _, client := range []string{"ip:10.0.0.1", "ip:10.0.0.2", "ip:10.0.0.3"} {
+		if _, _, retryAfter := limiter.allow(client, "/v1/list-races"); retryAfter != 0 {
+			t.Errorf("Expected %s to be allowed but it has to retry after %s", client, retryAfter)
+		}
+	}
+
+	if _, _, retryAfter := limiter.allow("ip:10.0.0.4", "/v1/list-races"); retryAfter == 0 {
+		t.Errorf("Expected the overflowing client to be limited")
+	}
+
+	if len(limiter.buckets) != 3 {
+		t.Errorf("Expected 2 buckets and the overflow one but got %d", len(limiter.buckets))
+	}
+}
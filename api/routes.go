@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// unmatchedRoute is the route of requests no route matches, so that made up paths do not each
+// get their own metric series or span name.
+const unmatchedRoute = "unmatched"
+
+// route is a method and path pattern served by the gateway, e.g. GET /v1/race/{id}.
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+}
+
+// routeTable resolves requests to the route patterns served by the gateway. It is filled as
+// routes are registered, and resolves requests before any middleware runs, so even requests
+// refused before reaching the mux are named by a known route.
+type routeTable struct {
+	routes []route
+}
+
+// add serves the pattern for the method.
+func (t *routeTable) add(method, pattern string) {
+	t.routes = append(t.routes, route{
+		method:   method,
+		pattern:  pattern,
+		segments: strings.Split(strings.TrimPrefix(pattern, "/"), "/"),
+	})
+}
+
+// addServices adds the routes the generated handlers serve, read from the google.api.http options
+// of the services' methods.
+func (t *routeTable) addServices(files ...protoreflect.FileDescriptor) {
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				if rule, ok := proto.GetExtension(methods.Get(j).Options(), annotations.E_Http).(*annotations.HttpRule); ok && rule != nil {
+					t.addRule(rule)
+				}
+			}
+		}
+	}
+}
+
+// addRule adds the route of an HTTP rule and of its additional bindings.
+func (t *routeTable) addRule(rule *annotations.HttpRule) {
+	switch pattern := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		t.add(http.MethodGet, pattern.Get)
+	case *annotations.HttpRule_Put:
+		t.add(http.MethodPut, pattern.Put)
+	case *annotations.HttpRule_Post:
+		t.add(http.MethodPost, pattern.Post)
+	case *annotations.HttpRule_Delete:
+		t.add(http.MethodDelete, pattern.Delete)
+	case *annotations.HttpRule_Patch:
+		t.add(http.MethodPatch, pattern.Patch)
+	case *annotations.HttpRule_Custom:
+		t.add(pattern.Custom.Kind, pattern.Custom.Path)
+	}
+
+	for _, binding := range rule.AdditionalBindings {
+		t.addRule(binding)
+	}
+}
+
+// handlePath registers a hand-written handler on the mux and adds its route.
+func (t *routeTable) handlePath(mux *runtime.ServeMux, method, pattern string, h runtime.HandlerFunc) error {
+	if err := mux.HandlePath(method, pattern, h); err != nil {
+		return err
+	}
+
+	t.add(method, pattern)
+
+	return nil
+}
+
+// resolve returns the pattern of the route serving the request, or unmatchedRoute. A {name}
+// segment matches any one segment of the path.
+func (t *routeTable) resolve(r *http.Request) string {
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+routes:
+	for _, route := range t.routes {
+		if route.method != r.Method || len(route.segments) != len(segments) {
+			continue
+		}
+
+		for i, segment := range route.segments {
+			if strings.HasPrefix(segment, "{") {
+				if segments[i] == "" {
+					continue routes
+				}
+			} else if segment != segments[i] {
+				continue routes
+			}
+		}
+
+		return route.pattern
+	}
+
+	return unmatchedRoute
+}
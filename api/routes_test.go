@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.neds.sh/matty/entain/api/proto/racing"
+	"git.neds.sh/matty/entain/api/proto/sports"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+func TestRouteTable_Resolve(t *testing.T) {
+	routes := &routeTable{}
+	routes.addServices(racing.File_racing_racing_proto, sports.File_sports_sports_proto)
+
+	if err := routes.handlePath(runtime.NewServeMux(), http.MethodGet, "/v1/sports-event/{id}/watch", func(http.ResponseWriter, *http.Request, map[string]string) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{http.MethodPost, "/v1/list-races", "/v1/list-races"},
+		{http.MethodGet, "/v1/race/42", "/v1/race/{id}"},
+		{http.MethodPost, "/v1/race/42/status", "/v1/race/{id}/status"},
+		{http.MethodPatch, "/v1/admin/race/42", "/v1/admin/race/{race.id}"},
+		{http.MethodGet, "/v1/sports-event/7/markets", "/v1/sports-event/{event_id}/markets"},
+		{http.MethodGet, "/v1/sports-event/7/watch", "/v1/sports-event/{id}/watch"},
+		{http.MethodGet, "/v1/race/abc", "/v1/race/{id}"},
+		{http.MethodDelete, "/v1/race/42", unmatchedRoute},
+		{http.MethodGet, "/v1/race/", unmatchedRoute},
+		{http.MethodGet, "/v1/race/42/runner/3/odds", unmatchedRoute},
+		{http.MethodGet, "/x/abc1", unmatchedRoute},
+	} {
+		if got := routes.resolve(httptest.NewRequest(c.method, c.path, nil)); got != c.expected {
+			t.Errorf("Expected route %s for %s %s but got %s", c.expected, c.method, c.path, got)
+		}
+	}
+}
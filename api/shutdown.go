@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// shutdownKey is the context key of the channel closed once the server starts shutting down.
+type shutdownKey struct{}
+
+// newServer serves handler on endpoint. Its requests can tell when it starts shutting down, so
+// event streams end then rather than holding the shutdown up until the grace period is over.
+func newServer(endpoint string, handler http.Handler) *http.Server {
+	closing := make(chan struct{})
+
+	server := &http.Server{
+		Addr:    endpoint,
+		Handler: handler,
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), shutdownKey{}, (<-chan struct{})(closing))
+		},
+	}
+	server.RegisterOnShutdown(func() {
+		close(closing)
+	})
+
+	return server
+}
+
+// shuttingDown returns the channel closed once the server of the request starts shutting down.
+// Outside a server it is nil, so it never fires.
+func shuttingDown(ctx context.Context) <-chan struct{} {
+	closing, _ := ctx.Value(shutdownKey{}).(<-chan struct{})
+	return closing
+}
+
+// shutdownGracefully stops accepting requests and waits for those in flight to finish, closing
+// the connections left once the grace period is over.
+func shutdownGracefully(server *http.Server, grace time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("grace period over, closing the requests left: %s\n", err)
+		server.Close()
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestShutdownGracefully_EndsEventStreams(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The stream never sends anything, as a quiet watch would
+	server := newServer(listener.Addr().String(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveSSE(w, r, func() (string, proto.Message, error) {
+			<-r.Context().Done()
+			return "", nil, r.Context().Err()
+		})
+	}))
+	go server.Serve(listener)
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/v1/watch-races")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	start := time.Now()
+	stopped := make(chan struct{})
+	go func() {
+		shutdownGracefully(server, 5*time.Second)
+		close(stopped)
+	}()
+
+	body := bufio.NewReader(resp.Body)
+	event, _ := body.ReadString('\n')
+	data, _ := body.ReadString('\n')
+	if event != "event: error\n" || !strings.Contains(data, "shutting down") {
+		t.Errorf("Expected a shutting down error event but got %q %q", event, data)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the server to stop")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the stream not to hold the shutdown up but it took %s", elapsed)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.neds.sh/matty/entain/api/proto/racing"
+	"git.neds.sh/matty/entain/api/proto/sports"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment, so proxies do not time it out.
+const sseKeepAlive = 15 * time.Second
+
+// registerWatchRaces serves the WatchRaces stream as Server-Sent Events on GET /v1/watch-races.
+// The filter is read from the query string, e.g. ?filter.meeting_ids=1&filter.statuses=OPEN.
+func registerWatchRaces(ctx context.Context, mux *runtime.ServeMux, routes *routeTable, endpoint string, opts []grpc.DialOption) error {
+	conn, err := dialBackend(ctx, endpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	client := racing.NewRacingClient(conn)
+
+	return routes.handlePath(mux, http.MethodGet, "/v1/watch-races", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		var in racing.WatchRacesRequest
+		if err := runtime.PopulateQueryParameters(&in, r.URL.Query(), utilities.NewDoubleArray(nil)); err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		stream, err := client.WatchRaces(r.Context(), &in)
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+			return
+		}
+
+		// Each event is named after its type.
+		serveSSE(w, r, func() (string, proto.Message, error) {
+			event, err := stream.Recv()
+			if err != nil {
+				return "", nil, err
+			}
+
+			return event.Type.String(), event, nil
+		})
+	})
+}
+
+// registerWatchEvent serves the WatchEvent stream as Server-Sent Events on
+// GET /v1/sports-event/{id}/watch. Every message carries the whole sport event.
+func registerWatchEvent(ctx context.Context, mux *runtime.ServeMux, routes *routeTable, endpoint string, opts []grpc.DialOption) error {
+	conn, err := dialBackend(ctx, endpoint, opts)
+	if err != nil {
+		return err
+	}
+
+	client := sports.NewSportsClient(conn)
+
+	return routes.handlePath(mux, http.MethodGet, "/v1/sports-event/{id}/watch", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		id, err := strconv.ParseInt(params["id"], 10, 64)
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, status.Errorf(codes.InvalidArgument, "invalid id %q", params["id"]))
+			return
+		}
+
+		stream, err := client.WatchEvent(r.Context(), &sports.WatchEventRequest{Id: id})
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+			return
+		}
+
+		serveSSE(w, r, func() (string, proto.Message, error) {
+			event, err := stream.Recv()
+			if err != nil {
+				return "", nil, err
+			}
+
+			return "", event, nil
+		})
+	})
+}
+
+// serveSSE relays the messages returned by recv to the client as Server-Sent Events, until the
+// stream fails, the client goes away or the server shuts down. Messages with an empty name are sent as unnamed events.
+func serveSSE(w http.ResponseWriter, r *http.Request, recv func() (string, proto.Message, error)) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	type message struct {
+		name string
+		msg  proto.Message
+	}
+
+	messages := make(chan message)
+	errs := make(chan error, 1)
+
+	go func() {
+		for {
+			name, msg, err := recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			select {
+			case messages <- message{name, msg}:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-shuttingDown(r.Context()):
+			// Clients reconnect, to another instance
+			fmt.Fprint(w, "event: error\ndata: server is shutting down\n\n")
+			flusher.Flush()
+			return
+		case err := <-errs:
+			// The headers are already sent, so the error can only be reported as an event.
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", strings.ReplaceAll(status.Convert(err).Message(), "\n", " "))
+			flusher.Flush()
+			return
+		case m := <-messages:
+			data, err := protoJSON.Marshal(m.msg)
+			if err != nil {
+				return
+			}
+
+			if m.name != "" {
+				fmt.Fprintf(w, "event: %s\n", m.name)
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authorizer checks the calls a gRPC server receives carry a bearer token for the role their
+// method needs, so that a service does not rely on the gateway to have checked it.
+type Authorizer struct {
+	verifier    *Verifier
+	methodRoles map[string]string
+}
+
+// NewAuthorizer requires the role listed in methodRoles for each method, by its full name. Every
+// other method is public and needs no token.
+func NewAuthorizer(verifier *Verifier, methodRoles map[string]string) *Authorizer {
+	return &Authorizer{verifier: verifier, methodRoles: methodRoles}
+}
+
+// UnaryInterceptor refuses unary calls the caller's role is not allowed to make.
+func (a *Authorizer) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamInterceptor refuses streams the caller's role is not allowed to open.
+func (a *Authorizer) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// authorize checks the bearer token in the metadata grants a role that may call the method. Public
+// methods need no token. Allowed calls to other methods are logged along with who made them.
+func (a *Authorizer) authorize(ctx context.Context, method string) error {
+	required, ok := a.methodRoles[method]
+	if !ok {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(MetadataKey)
+	if len(values) == 0 {
+		return status.Errorf(codes.Unauthenticated, "a token with the %s role is required", required)
+	}
+
+	id, err := a.verifier.Verify(values[0])
+	if err != nil {
+		return err
+	}
+
+	if !Allows(id.Role, required) {
+		return status.Errorf(codes.PermissionDenied, "%s requires the %s role", method, required)
+	}
+
+	log.Printf("%s called by %q as %s\n", method, id.Subject, id.Role)
+
+	return nil
+}
@@ -0,0 +1,169 @@
+// Package auth verifies the bearer tokens callers present, the same way in the API gateway and
+// in the services behind it, and checks the role a token grants against the methods it calls.
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MicahParks/keyfunc/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Roles, from least to most privileged. A role may call everything the roles before it can.
+const (
+	RolePublic = "public"
+	RoleTrader = "trader"
+	RoleAdmin  = "admin"
+)
+
+// roleRanks orders the roles by privilege.
+var roleRanks = map[string]int{RolePublic: 0, RoleTrader: 1, RoleAdmin: 2}
+
+// Allows reports whether a role may call what the required role can.
+func Allows(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required]
+}
+
+// MetadataKey carries the caller's bearer token from the gateway to the services, which verify
+// it again rather than trust the gateway.
+const MetadataKey = "authorization"
+
+// Claims are the token claims read from a bearer token.
+type Claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Identity is who a token was issued to, and the token that proves it.
+type Identity struct {
+	Subject string
+	Role    string
+	Token   string
+}
+
+// Verifier verifies bearer tokens.
+type Verifier struct {
+	keyfunc jwt.Keyfunc
+	parser  *jwt.Parser
+}
+
+// NewVerifier verifies tokens against the keys of a JWKS file, or against a single static key: a
+// PEM encoded public key, or else a shared HMAC secret. Without either, every token is refused.
+func NewVerifier(jwksFile, keyFile, issuer, audience string) (*Verifier, error) {
+	var (
+		keys    jwt.Keyfunc
+		methods []string
+	)
+
+	switch {
+	case jwksFile != "" && keyFile != "":
+		return nil, errors.New("only one of a JWKS file and a key file can be given")
+	case jwksFile != "":
+		data, err := os.ReadFile(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+
+		jwks, err := keyfunc.NewJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWKS %s: %w", jwksFile, err)
+		}
+
+		keys = jwks.Keyfunc
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		key, keyMethods, err := parseStaticKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("reading key %s: %w", keyFile, err)
+		}
+
+		keys = func(*jwt.Token) (interface{}, error) { return key, nil }
+		methods = keyMethods
+	}
+
+	options := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if len(methods) > 0 {
+		options = append(options, jwt.WithValidMethods(methods))
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return &Verifier{keyfunc: keys, parser: jwt.NewParser(options...)}, nil
+}
+
+// parseStaticKey reads a PEM encoded public key, or takes the whole file as an HMAC secret. The
+// signing methods matching the key are returned too, so a token cannot pick another family.
+func parseStaticKey(data []byte) (crypto.PublicKey, []string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		secret := []byte(strings.TrimSpace(string(data)))
+		if len(secret) == 0 {
+			return nil, nil, errors.New("key is empty")
+		}
+
+		return secret, []string{"HS256", "HS384", "HS512"}, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return key, []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}, nil
+	case *ecdsa.PublicKey:
+		return key, []string{"ES256", "ES384", "ES512"}, nil
+	case ed25519.PublicKey:
+		return key, []string{"EdDSA"}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// Verify returns the identity the bearer token in an Authorization header was issued to. Every
+// error is an Unauthenticated status.
+func (v *Verifier) Verify(header string) (Identity, error) {
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header || token == "" {
+		return Identity{}, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+
+	if v.keyfunc == nil {
+		return Identity{}, status.Error(codes.Unauthenticated, "tokens are not accepted, no key is configured")
+	}
+
+	var c Claims
+	if _, err := v.parser.ParseWithClaims(token, &c, v.keyfunc); err != nil {
+		return Identity{}, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+
+	if c.Role == "" {
+		c.Role = RolePublic
+	}
+
+	if _, ok := roleRanks[c.Role]; !ok {
+		return Identity{}, status.Errorf(codes.Unauthenticated, "unknown role %q", c.Role)
+	}
+
+	return Identity{Subject: c.Subject, Role: c.Role, Token: token}, nil
+}
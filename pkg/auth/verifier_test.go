@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeFile writes data to a file in a temporary directory and returns its path.
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// sign issues a token for the subject and role, expiring after ttl.
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid, subject, role string, ttl time.Duration) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestVerify_StaticKey(t *testing.T) {
+	secret := []byte("a secret only the issuer and the gateway know")
+
+	verifier, err := NewVerifier("", writeFile(t, "secret", secret), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := verifier.Verify("Bearer " + sign(t, jwt.SigningMethodHS256, secret, "", "jane", RoleTrader, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "jane" || id.Role != RoleTrader {
+		t.Errorf("Expected jane as a trader but got %+v", id)
+	}
+
+	// Tokens without a role are public
+	id, err = verifier.Verify("Bearer " + sign(t, jwt.SigningMethodHS256, secret, "", "joe", "", time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Role != RolePublic {
+		t.Errorf("Expected role %s but got %s", RolePublic, id.Role)
+	}
+
+	for name, header := range map[string]string{
+		"expired":      "Bearer " + sign(t, jwt.SigningMethodHS256, secret, "", "jane", RoleTrader, -time.Minute),
+		"other key":    "Bearer " + sign(t, jwt.SigningMethodHS256, []byte("another secret"), "", "jane", RoleTrader, time.Minute),
+		"unknown role": "Bearer " + sign(t, jwt.SigningMethodHS256, secret, "", "jane", "owner", time.Minute),
+		"not bearer":   "Basic amFuZTpzZWNyZXQ=",
+		"malformed":    "Bearer not-a-token",
+	} {
+		if _, err := verifier.Verify(header); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected error code %v for the %s token but got %v", codes.Unauthenticated, name, status.Code(err))
+		}
+	}
+
+	// Without a key no token is accepted
+	verifier, err = NewVerifier("", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify("Bearer " + sign(t, jwt.SigningMethodHS256, secret, "", "jane", RoleTrader, time.Minute)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected error code %v but got %v", codes.Unauthenticated, status.Code(err))
+	}
+}
+
+func TestVerify_JWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"gateway-1","alg":"RS256","use":"sig","n":%q,"e":%q}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	)
+
+	verifier, err := NewVerifier(writeFile(t, "jwks.json", []byte(jwks)), "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := verifier.Verify("Bearer " + sign(t, jwt.SigningMethodRS256, key, "gateway-1", "ops", RoleAdmin, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "ops" || id.Role != RoleAdmin {
+		t.Errorf("Expected ops as an admin but got %+v", id)
+	}
+
+	// Keys are picked by their id
+	if _, err := verifier.Verify("Bearer " + sign(t, jwt.SigningMethodRS256, key, "gateway-2", "ops", RoleAdmin, time.Minute)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected error code %v but got %v", codes.Unauthenticated, status.Code(err))
+	}
+}
@@ -0,0 +1,46 @@
+// Package notify wakes the watchers of a service as soon as the service changes what they
+// watch, so they do not wait for their next poll.
+package notify
+
+import "sync"
+
+// Notifier wakes its subscribers each time it is notified of a change.
+type Notifier struct {
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
+// New creates a notifier with no subscribers.
+func New() *Notifier {
+	return &Notifier{subscribers: make(map[chan struct{}]struct{})}
+}
+
+// Subscribe returns a channel that receives a value after a change, and a function to stop
+// receiving.
+func (n *Notifier) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	n.subscribers[ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.subscribers, ch)
+		n.mu.Unlock()
+	}
+}
+
+// Notify wakes every subscriber. A subscriber that has not caught up with an earlier change
+// is not sent another, as it picks up every change since once it wakes.
+func (n *Notifier) Notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WatchDrainer ends the watch streams once the server starts shutting down. They last as long
+// as the client watches, so a graceful stop would otherwise wait for them until its grace period
+// is over. Ended with Unavailable, clients know to watch again, from another instance.
+type WatchDrainer struct {
+	once     sync.Once
+	draining chan struct{}
+}
+
+// NewWatchDrainer creates a drainer for a server, to be installed with StreamInterceptor.
+func NewWatchDrainer() *WatchDrainer {
+	return &WatchDrainer{draining: make(chan struct{})}
+}
+
+// Drain ends the open watch streams, and any opened afterwards.
+func (d *WatchDrainer) Drain() {
+	d.once.Do(func() {
+		close(d.draining)
+	})
+}
+
+// StreamInterceptor cancels the context of server streams once draining. Other streams are left
+// to finish.
+func (d *WatchDrainer) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !info.IsServerStream {
+		return handler(srv, ss)
+	}
+
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-d.draining:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := handler(srv, &drainedStream{ServerStream: ss, ctx: ctx})
+
+	select {
+	case <-d.draining:
+		return status.Error(codes.Unavailable, "server is shutting down")
+	default:
+		return err
+	}
+}
+
+// drainedStream is a server stream whose context is cancelled once draining.
+type drainedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *drainedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,69 @@
+// Package server holds what the gRPC servers of the services share to start and stop cleanly.
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// healthService is the prefix of the health checking methods, answered before the server is
+// ready so that it can report it is not.
+const healthService = "/grpc.health.v1.Health/"
+
+// ReadinessGate refuses the calls a server receives until it is ready, so that the port can be
+// opened for health checks while the server starts. Callers get Unavailable and know to retry,
+// rather than errors from a database that is not set up yet.
+type ReadinessGate struct {
+	once  sync.Once
+	ready chan struct{}
+}
+
+// NewReadinessGate creates a gate for a server, to be installed with UnaryInterceptor and
+// StreamInterceptor.
+func NewReadinessGate() *ReadinessGate {
+	return &ReadinessGate{ready: make(chan struct{})}
+}
+
+// Ready lets the calls through from now on.
+func (g *ReadinessGate) Ready() {
+	g.once.Do(func() {
+		close(g.ready)
+	})
+}
+
+// UnaryInterceptor refuses unary calls until the server is ready.
+func (g *ReadinessGate) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := g.check(info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamInterceptor refuses streams until the server is ready.
+func (g *ReadinessGate) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := g.check(info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// check lets health checks through at any time, and other methods once ready.
+func (g *ReadinessGate) check(method string) error {
+	if strings.HasPrefix(method, healthService) {
+		return nil
+	}
+
+	select {
+	case <-g.ready:
+		return nil
+	default:
+		return status.Error(codes.Unavailable, "server is starting")
+	}
+}
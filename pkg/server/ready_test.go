@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadinessGate_RefusesUntilReady(t *testing.T) {
+	gate := NewReadinessGate()
+
+	call := func(method string) error {
+		_, err := gate.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	open := func(method string) error {
+		return gate.StreamInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, ss grpc.ServerStream) error {
+			return nil
+		})
+	}
+
+	if err := call("/racing.Racing/ListRaces"); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected error code %v before ready but got %v", codes.Unavailable, err)
+	}
+	if err := open("/racing.Racing/WatchRaces"); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected error code %v before ready but got %v", codes.Unavailable, err)
+	}
+
+	// Health checks are answered throughout
+	if err := call("/grpc.health.v1.Health/Check"); err != nil {
+		t.Errorf("Expected health checks to be answered before ready but got %v", err)
+	}
+
+	gate.Ready()
+
+	if err := call("/racing.Racing/ListRaces"); err != nil {
+		t.Errorf("Expected calls to be let through once ready but got %v", err)
+	}
+	if err := open("/racing.Racing/WatchRaces"); err != nil {
+		t.Errorf("Expected streams to be let through once ready but got %v", err)
+	}
+}
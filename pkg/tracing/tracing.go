@@ -0,0 +1,95 @@
+// Package tracing sets up the OpenTelemetry tracing shared by the API gateway and the services.
+package tracing
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// flushTimeout is how long the spans left are exported for on shutdown.
+const flushTimeout = 5 * time.Second
+
+// Setup exports the spans of the service through OTLP over gRPC to otlpEndpoint, and as JSON
+// lines to traceFile for local runs, - meaning stdout. With neither, no spans are recorded though
+// the trace context of incoming calls is still passed on. The returned function flushes the
+// spans left, within flushTimeout, and closes the exporters.
+func Setup(ctx context.Context, service, otlpEndpoint string, otlpInsecure bool, traceFile string) (func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if otlpEndpoint == "" && traceFile == "" {
+		return func() {}, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(service)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	if otlpEndpoint != "" {
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(otlpEndpoint)}
+		if otlpInsecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+
+		exporter, err := otlptracegrpc.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	var file *os.File
+
+	if traceFile != "" {
+		var out io.Writer = os.Stdout
+		if traceFile != "-" {
+			file, err = os.OpenFile(traceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return nil, err
+			}
+
+			out = file
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		defer cancel()
+
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Printf("failed flushing trace spans: %s\n", err)
+		}
+
+		if file != nil {
+			file.Close()
+		}
+	}, nil
+}
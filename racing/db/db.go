@@ -1,23 +1,45 @@
 package db
 
 import (
+	"database/sql"
+	"math"
 	"time"
 
+	"git.neds.sh/matty/entain/racing/proto/racing"
 	"syreclabs.com/go/faker"
 )
 
+const (
+	// seedMeetings is the number of dummy meetings seeded, which seeded races are spread across.
+	seedMeetings = 10
+	// seedRaces is the number of dummy races seeded.
+	seedRaces = 100
+	// seedRunnersPerRace is the number of dummy runners seeded in each race.
+	seedRunnersPerRace = 8
+)
+
 func (r *racesRepo) seed() error {
-	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS races (id INTEGER PRIMARY KEY, meeting_id INTEGER, name TEXT, number INTEGER, visible INTEGER, advertised_start_time DATETIME)`)
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS races (id INTEGER PRIMARY KEY, meeting_id INTEGER, name TEXT, number INTEGER, visible INTEGER, advertised_start_time DATETIME, status INTEGER, version INTEGER NOT NULL DEFAULT 1)`)
 	if err == nil {
 		_, err = statement.Exec()
 	}
 
-	for i := 1; i <= 100; i++ {
+	// Databases created before race statuses were stored need the column added.
+	if err == nil {
+		err = addColumnIfMissing(r.db, "races", "status", "INTEGER")
+	}
+
+	// Likewise for the version used by the admin API for optimistic concurrency.
+	if err == nil {
+		err = addColumnIfMissing(r.db, "races", "version", "INTEGER NOT NULL DEFAULT 1")
+	}
+
+	for i := 1; i <= seedRaces; i++ {
 		statement, err = r.db.Prepare(`INSERT OR IGNORE INTO races(id, meeting_id, name, number, visible, advertised_start_time) VALUES (?,?,?,?,?,?)`)
 		if err == nil {
 			_, err = statement.Exec(
 				i,
-				faker.Number().Between(1, 10),
+				faker.Number().Between(1, seedMeetings),
 				faker.Team().Name(),
 				faker.Number().Between(1, 12),
 				faker.Number().Between(0, 1),
@@ -28,3 +50,120 @@ func (r *racesRepo) seed() error {
 
 	return err
 }
+
+func (r *meetingsRepo) seed() error {
+	countryChoices := []string{"AUS", "NZL", "GBR", "IRL", "USA"}
+
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS meetings (id INTEGER PRIMARY KEY, venue TEXT, country TEXT, race_type INTEGER, date TEXT)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	for i := 1; i <= seedMeetings; i++ {
+		statement, err = r.db.Prepare(`INSERT OR IGNORE INTO meetings(id, venue, country, race_type, date) VALUES (?,?,?,?,?)`)
+		if err == nil {
+			_, err = statement.Exec(
+				i,
+				faker.Address().City(),
+				faker.RandomChoice(countryChoices),
+				faker.Number().Between(0, len(racing.RaceType_name)-1),
+				faker.Time().Between(time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 2)).Format("2006-01-02"),
+			)
+		}
+	}
+
+	return err
+}
+
+func (r *runnersRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS runners (id INTEGER PRIMARY KEY, race_id INTEGER, number INTEGER, name TEXT, barrier INTEGER, jockey TEXT, trainer TEXT, weight REAL, scratched INTEGER)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	for race := 1; race <= seedRaces; race++ {
+		for number := 1; number <= seedRunnersPerRace; number++ {
+			statement, err = r.db.Prepare(`INSERT OR IGNORE INTO runners(id, race_id, number, name, barrier, jockey, trainer, weight, scratched) VALUES (?,?,?,?,?,?,?,?,?)`)
+			if err == nil {
+				_, err = statement.Exec(
+					(race-1)*seedRunnersPerRace+number,
+					race,
+					number,
+					faker.Lorem().Word()+" "+faker.Name().FirstName(),
+					// Spread barriers so each runner in a race jumps from a different one.
+					(race+number)%seedRunnersPerRace+1,
+					faker.Name().Name(),
+					faker.Name().Name(),
+					faker.Number().Between(54, 60),
+					faker.RandomChoice([]string{"0", "0", "0", "0", "0", "0", "0", "1"}),
+				)
+			}
+		}
+	}
+
+	return err
+}
+
+func (r *pricesRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS prices (id INTEGER PRIMARY KEY, runner_id INTEGER, win REAL, place REAL, created_at DATETIME)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	// Open every seeded runner with a single price.
+	for runner := 1; runner <= seedRaces*seedRunnersPerRace; runner++ {
+		win := float64(faker.Number().NumberInt(3)%2850+150) / 100
+
+		statement, err = r.db.Prepare(`INSERT OR IGNORE INTO prices(id, runner_id, win, place, created_at) VALUES (?,?,?,?,?)`)
+		if err == nil {
+			_, err = statement.Exec(
+				runner,
+				runner,
+				win,
+				// Place odds pay roughly a quarter of the win odds.
+				math.Round(((win-1)/4+1)*100)/100,
+				time.Now().AddDate(0, 0, -1).UTC().Format(time.RFC3339),
+			)
+		}
+	}
+
+	return err
+}
+
+// Races are resulted through SetResult, so no dummy results are seeded.
+func (r *resultsRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS results (id INTEGER PRIMARY KEY, race_id INTEGER, runner_id INTEGER, position INTEGER, win_dividend REAL, place_dividend REAL)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	return err
+}
+
+// addColumnIfMissing adds a column to an existing table, so databases seeded by an older
+// version of the service keep working.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + definition)
+
+	return err
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MeetingsRepo provides repository access to meetings.
+type MeetingsRepo interface {
+	// Init will initialise our meetings repository.
+	Init() error
+
+	// List will return a list of meetings.
+	List(ctx context.Context, filter *racing.ListMeetingsRequestFilter) ([]*racing.Meeting, error)
+
+	// Get will return one meeting.
+	Get(ctx context.Context, id int64) (*racing.Meeting, error)
+}
+
+type meetingsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewMeetingsRepo creates a new meetings repository.
+func NewMeetingsRepo(db *sql.DB) MeetingsRepo {
+	return &meetingsRepo{db: db}
+}
+
+// Init prepares the meeting repository dummy data.
+func (r *meetingsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with some dummy meetings.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *meetingsRepo) List(ctx context.Context, filter *racing.ListMeetingsRequestFilter) ([]*racing.Meeting, error) {
+	ctx, done := observeQuery(ctx, "meetings", "List")
+	defer done()
+
+	var (
+		err   error
+		query string
+		args  []interface{}
+	)
+
+	query = getMeetingQueries()[meetingsList]
+
+	query, args = r.applyFilter(query, filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanMeetings(rows)
+}
+
+func (r *meetingsRepo) applyFilter(query string, filter *racing.ListMeetingsRequestFilter) (string, []interface{}) {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+
+	if filter != nil {
+		if len(filter.Ids) > 0 {
+			clauses = append(clauses, "id IN ("+strings.Repeat("?,", len(filter.Ids)-1)+"?)")
+
+			for _, meetingID := range filter.Ids {
+				args = append(args, meetingID)
+			}
+		}
+
+		if len(filter.RaceTypes) > 0 {
+			clauses = append(clauses, "race_type IN ("+strings.Repeat("?,", len(filter.RaceTypes)-1)+"?)")
+
+			for _, raceType := range filter.RaceTypes {
+				args = append(args, raceType)
+			}
+		}
+
+		if filter.Date != "" {
+			clauses = append(clauses, "date = ?")
+			args = append(args, filter.Date)
+		}
+	}
+
+	if len(clauses) != 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	query += " ORDER BY date ASC, id ASC"
+
+	return query, args
+}
+
+func (r *meetingsRepo) scanMeetings(
+	rows *sql.Rows,
+) ([]*racing.Meeting, error) {
+	var meetings []*racing.Meeting
+
+	for rows.Next() {
+		var meeting racing.Meeting
+
+		if err := rows.Scan(&meeting.Id, &meeting.Venue, &meeting.Country, &meeting.RaceType, &meeting.Date); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		meetings = append(meetings, &meeting)
+	}
+
+	return meetings, nil
+}
+
+func (r *meetingsRepo) Get(ctx context.Context, id int64) (*racing.Meeting, error) {
+	ctx, done := observeQuery(ctx, "meetings", "Get")
+	defer done()
+
+	var meeting racing.Meeting
+
+	row := r.db.QueryRowContext(ctx, getMeetingQueries()[meetingById], id)
+
+	if err := row.Scan(&meeting.Id, &meeting.Venue, &meeting.Country, &meeting.RaceType, &meeting.Date); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "Meeting not found")
+		}
+
+		return nil, err
+	}
+
+	return &meeting, nil
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "racing_db_query_duration_seconds",
+	Help:    "Time taken by the SQL queries of each repo method, by repo and method.",
+	Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16),
+}, []string{"repo", "method"})
+
+// tracerName names the tracer of the repo spans.
+const tracerName = "git.neds.sh/matty/entain/racing/db"
+
+// observeQuery starts timing a repo method, in a span of the trace in ctx. It returns the span's
+// context, for the queries to run in, and the function ending the span and recording the
+// duration. It is deferred at the start of the method, so the time to scan the rows is included.
+func observeQuery(ctx context.Context, repo, method string) (context.Context, func()) {
+	start := time.Now()
+
+	// The tracer is looked up on each call rather than once, so spans go to whichever provider
+	// is installed at the time.
+	ctx, span := otel.Tracer(tracerName).Start(ctx, repo+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemSqlite),
+	)
+
+	return ctx, func() {
+		span.End()
+		queryDuration.WithLabelValues(repo, method).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxPageSize caps the number of races a single ListRaces page may return.
+const maxPageSize = 1000
+
+// pageCursor marks the last race of a page. It is the keyset the next page continues after,
+// so pages stay stable even when races are inserted between calls.
+type pageCursor struct {
+	// OrderBy is the ordering the token was issued for, nil when the list was ordered by id only.
+	OrderBy *racing.OrderBy `json:"o,omitempty"`
+	// AdvertisedStartTime of the last race on the page.
+	AdvertisedStartTime time.Time `json:"t"`
+	// ID of the last race on the page, used to break ties on advertised start time.
+	ID int64 `json:"id"`
+}
+
+// encodePageToken turns the last race of a page into an opaque page token.
+func encodePageToken(race *racing.Race, orderBy *racing.OrderBy) (string, error) {
+	b, err := json.Marshal(pageCursor{
+		OrderBy:             orderBy,
+		AdvertisedStartTime: race.AdvertisedStartTime.AsTime(),
+		ID:                  race.Id,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodePageToken parses a page token, returning nil for an empty token. A token issued for
+// a different ordering is rejected, as its keyset would not line up with the requested order.
+func decodePageToken(token string, orderBy *racing.OrderBy) (*pageCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+
+	if (cursor.OrderBy == nil) != (orderBy == nil) || (orderBy != nil && *cursor.OrderBy != *orderBy) {
+		return nil, status.Error(codes.InvalidArgument, "page token does not match the requested order")
+	}
+
+	return &cursor, nil
+}
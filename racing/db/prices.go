@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PricesRepo provides repository access to runner prices. Prices are only ever added, so
+// the rows for a runner form its price history and the latest row is its current price.
+type PricesRepo interface {
+	// Init will initialise our prices repository.
+	Init() error
+
+	// ListCurrent will return the current price of each priced runner in a race.
+	ListCurrent(ctx context.Context, raceID int64) ([]*racing.Price, error)
+
+	// ListHistory will return every price of a runner, oldest first.
+	ListHistory(ctx context.Context, runnerID int64) ([]*racing.Price, error)
+
+	// Insert will add a new current price for a runner, as long as its race is still OPEN.
+	Insert(ctx context.Context, runnerID int64, win, place float64, at time.Time) (*racing.Price, error)
+}
+
+type pricesRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewPricesRepo creates a new prices repository.
+func NewPricesRepo(db *sql.DB) PricesRepo {
+	return &pricesRepo{db: db}
+}
+
+// Init prepares the price repository dummy data.
+func (r *pricesRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with some dummy prices.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *pricesRepo) ListCurrent(ctx context.Context, raceID int64) ([]*racing.Price, error) {
+	ctx, done := observeQuery(ctx, "prices", "ListCurrent")
+	defer done()
+
+	rows, err := r.db.QueryContext(ctx, getPriceQueries()[pricesCurrentByRace], raceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanPrices(rows)
+}
+
+func (r *pricesRepo) ListHistory(ctx context.Context, runnerID int64) ([]*racing.Price, error) {
+	ctx, done := observeQuery(ctx, "prices", "ListHistory")
+	defer done()
+
+	rows, err := r.db.QueryContext(ctx, getPriceQueries()[pricesByRunner], runnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanPrices(rows)
+}
+
+func (r *pricesRepo) Insert(ctx context.Context, runnerID int64, win, place float64, at time.Time) (*racing.Price, error) {
+	ctx, done := observeQuery(ctx, "prices", "Insert")
+	defer done()
+
+	res, err := r.db.ExecContext(ctx, getPriceQueries()[priceInsert], runnerID, win, place, at.UTC(), runnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	// The race left OPEN between the caller's checks and the insert.
+	if inserted == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "Prices are locked as the race is no longer open")
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := ptypes.TimestampProto(at)
+	if err != nil {
+		return nil, err
+	}
+
+	return &racing.Price{Id: id, RunnerId: runnerID, Win: win, Place: place, Timestamp: ts}, nil
+}
+
+func (r *pricesRepo) scanPrices(
+	rows *sql.Rows,
+) ([]*racing.Price, error) {
+	var prices []*racing.Price
+
+	for rows.Next() {
+		var price racing.Price
+		var at time.Time
+
+		if err := rows.Scan(&price.Id, &price.RunnerId, &price.Win, &price.Place, &at); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		ts, err := ptypes.TimestampProto(at)
+		if err != nil {
+			return nil, err
+		}
+
+		price.Timestamp = ts
+
+		prices = append(prices, &price)
+	}
+
+	return prices, nil
+}
@@ -1,10 +1,24 @@
 package db
 
 const (
-	racesList = "list"
-	raceById  = "getById"
+	racesList        = "list"
+	raceById         = "getById"
+	raceUpdateStatus = "updateStatus"
+	raceInsert       = "insert"
+	raceUpdate       = "update"
+	raceDelete       = "delete"
+	raceNextClose    = "nextClose"
 )
 
+// startTimeKey normalises advertised_start_time for comparison and ordering, as rows may be
+// stored in either RFC3339 or SQLite's own datetime layout.
+const startTimeKey = "julianday(advertised_start_time)"
+
+// raceStatus resolves a race's racing.Status. A stored lifecycle status wins, except OPEN (0),
+// which like no status at all falls back to the clock: CLOSED (1) once the advertised start time
+// has passed, OPEN otherwise. It is computed in SQL so it can be filtered on.
+const raceStatus = "CASE WHEN status IS NOT NULL AND status != 0 THEN status WHEN " + startTimeKey + " < julianday('now') THEN 1 ELSE 0 END"
+
 func getRaceQueries() map[string]string {
 	return map[string]string{
 		racesList: `
@@ -14,7 +28,9 @@ func getRaceQueries() map[string]string {
 				name, 
 				number, 
 				visible, 
-				advertised_start_time 
+				advertised_start_time, 
+				` + raceStatus + ` AS status, 
+				version
 			FROM races
 		`,
 		raceById: `
@@ -24,9 +40,191 @@ func getRaceQueries() map[string]string {
 				name, 
 				number, 
 				visible, 
-				advertised_start_time
+				advertised_start_time, 
+				` + raceStatus + ` AS status, 
+				version
+			FROM races
+			WHERE id = $1
+		`,
+		// Only updates when the race is still in the status the transition was checked against.
+		raceUpdateStatus: `
+			UPDATE races
+			SET status = ?, version = version + 1
+			WHERE id = ? AND ` + raceStatus + ` = ?
+		`,
+		raceInsert: `
+			INSERT INTO races (meeting_id, name, number, visible, advertised_start_time, version)
+			VALUES (?,?,?,?,?,1)
+		`,
+		// Only updates when the race is still at the version the change was made against.
+		raceUpdate: `
+			UPDATE races
+			SET meeting_id = ?, name = ?, number = ?, visible = ?, advertised_start_time = ?, version = version + 1
+			WHERE id = ? AND version = ?
+		`,
+		raceDelete: `DELETE FROM races WHERE id = ? AND version = ?`,
+		// The advertised start time, in Unix seconds, of the next race still open by the clock.
+		raceNextClose: `
+			SELECT MIN((` + startTimeKey + ` - 2440587.5) * 86400.0)
 			FROM races
+			WHERE ` + raceStatus + ` = 0
+		`,
+	}
+}
+
+const (
+	meetingsList = "list"
+	meetingById  = "getById"
+)
+
+func getMeetingQueries() map[string]string {
+	return map[string]string{
+		meetingsList: `
+			SELECT 
+				id, 
+				venue, 
+				country, 
+				race_type, 
+				date 
+			FROM meetings
+		`,
+		meetingById: `
+			SELECT 
+				id, 
+				venue, 
+				country, 
+				race_type, 
+				date 
+			FROM meetings
 			WHERE id = $1
 		`,
 	}
 }
+
+const (
+	runnersByRace       = "listByRace"
+	runnerById          = "getById"
+	runnersDeleteByRace = "deleteByRace"
+)
+
+func getRunnerQueries() map[string]string {
+	return map[string]string{
+		runnersByRace: `
+			SELECT 
+				id, 
+				race_id, 
+				number, 
+				name, 
+				barrier, 
+				jockey, 
+				trainer, 
+				weight, 
+				scratched 
+			FROM runners
+			WHERE race_id = ?
+			ORDER BY number ASC
+		`,
+		runnerById: `
+			SELECT 
+				id, 
+				race_id, 
+				number, 
+				name, 
+				barrier, 
+				jockey, 
+				trainer, 
+				weight, 
+				scratched 
+			FROM runners
+			WHERE id = ?
+		`,
+		runnersDeleteByRace: `DELETE FROM runners WHERE race_id = ?`,
+	}
+}
+
+const (
+	pricesCurrentByRace = "listCurrentByRace"
+	pricesByRunner      = "listByRunner"
+	priceInsert         = "insert"
+	pricesDeleteByRace  = "deleteByRace"
+)
+
+func getPriceQueries() map[string]string {
+	return map[string]string{
+		// The latest price of a runner is its current price.
+		pricesCurrentByRace: `
+			SELECT 
+				p.id, 
+				p.runner_id, 
+				p.win, 
+				p.place, 
+				p.created_at 
+			FROM prices p
+			JOIN runners ru ON ru.id = p.runner_id
+			WHERE ru.race_id = ?
+				AND p.id = (SELECT MAX(id) FROM prices WHERE runner_id = p.runner_id)
+			ORDER BY ru.number ASC
+		`,
+		pricesByRunner: `
+			SELECT 
+				id, 
+				runner_id, 
+				win, 
+				place, 
+				created_at 
+			FROM prices
+			WHERE runner_id = ?
+			ORDER BY id ASC
+		`,
+		// Only inserts while the runner's race is still OPEN, so prices lock as soon as it leaves.
+		priceInsert: `
+			INSERT INTO prices (runner_id, win, place, created_at)
+			SELECT ?, ?, ?, ?
+			WHERE EXISTS (
+				SELECT 1 FROM runners ru
+				JOIN races ON races.id = ru.race_id
+				WHERE ru.id = ? AND ` + raceStatus + ` = 0
+			)
+		`,
+		pricesDeleteByRace: `DELETE FROM prices WHERE runner_id IN (SELECT id FROM runners WHERE race_id = ?)`,
+	}
+}
+
+const (
+	resultsList         = "list"
+	resultsByRace       = "listByRace"
+	resultsDeleteByRace = "deleteByRace"
+	resultInsert        = "insert"
+)
+
+func getResultQueries() map[string]string {
+	return map[string]string{
+		resultsList: `
+			SELECT 
+				res.race_id, 
+				res.runner_id, 
+				res.position, 
+				res.win_dividend, 
+				res.place_dividend 
+			FROM results res
+			JOIN races ON races.id = res.race_id
+			LEFT JOIN meetings ON meetings.id = races.meeting_id
+		`,
+		resultsByRace: `
+			SELECT 
+				race_id, 
+				runner_id, 
+				position, 
+				win_dividend, 
+				place_dividend 
+			FROM results
+			WHERE race_id = ?
+			ORDER BY position ASC, runner_id ASC
+		`,
+		resultsDeleteByRace: `DELETE FROM results WHERE race_id = ?`,
+		resultInsert: `
+			INSERT INTO results (race_id, runner_id, position, win_dividend, place_dividend)
+			VALUES (?,?,?,?,?)
+		`,
+	}
+}
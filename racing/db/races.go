@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 	"sync"
@@ -19,11 +20,29 @@ type RacesRepo interface {
 	// Init will initialise our races repository.
 	Init() error
 
-	// List will return a list of races.
-	List(filter *racing.ListRacesRequestFilter) ([]*racing.Race, error)
+	// List will return a page of races along with the token for the next page.
+	// A pageSize of zero returns all matching races.
+	List(ctx context.Context, filter *racing.ListRacesRequestFilter, pageSize int32, pageToken string) ([]*racing.Race, string, error)
 
 	//Get one race
-	Get(id int64) (*racing.Race, error)
+	Get(ctx context.Context, id int64) (*racing.Race, error)
+
+	// UpdateStatus stores a new status for a race, provided it is still in status from. The results
+	// of a race moved to ABANDONED are deleted along with it.
+	UpdateStatus(ctx context.Context, id int64, from racing.Status, to racing.Status) (*racing.Race, error)
+
+	// Create stores a new race, assigning its id.
+	Create(ctx context.Context, race *racing.Race) (*racing.Race, error)
+
+	// Update stores the fields of a race, provided it is still at race.Version.
+	Update(ctx context.Context, race *racing.Race) (*racing.Race, error)
+
+	// Delete removes a race with its runners, prices and results, provided it is still at version.
+	Delete(ctx context.Context, id int64, version int64) error
+
+	// NextClose returns when the next race to close with the clock does so. ok is false when no
+	// race is still open by the clock.
+	NextClose(ctx context.Context) (closes time.Time, ok bool, err error)
 }
 
 type racesRepo struct {
@@ -48,57 +67,142 @@ func (r *racesRepo) Init() error {
 	return err
 }
 
-func (r *racesRepo) List(filter *racing.ListRacesRequestFilter) ([]*racing.Race, error) {
+func (r *racesRepo) List(ctx context.Context, filter *racing.ListRacesRequestFilter, pageSize int32, pageToken string) ([]*racing.Race, string, error) {
+	ctx, done := observeQuery(ctx, "races", "List")
+	defer done()
+
 	var (
 		err   error
 		query string
 		args  []interface{}
 	)
 
+	if pageSize < 0 {
+		return nil, "", status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	var orderBy *racing.OrderBy
+	if filter != nil {
+		orderBy = filter.OrderBy
+	}
+
+	if filter != nil && filter.StartAfter != nil && filter.StartBefore != nil &&
+		!filter.StartAfter.AsTime().Before(filter.StartBefore.AsTime()) {
+		return nil, "", status.Error(codes.InvalidArgument, "start_after must be before start_before")
+	}
+
+	cursor, err := decodePageToken(pageToken, orderBy)
+	if err != nil {
+		return nil, "", err
+	}
+
 	query = getRaceQueries()[racesList]
 
-	query, args = r.applyFilter(query, filter)
+	query, args = r.applyFilter(query, filter, cursor)
 
-	rows, err := r.db.Query(query, args...)
+	// Fetch one extra race so we know whether there is another page.
+	if pageSize > 0 {
+		query += " LIMIT ?"
+		args = append(args, pageSize+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	races, err := r.scanRaces(rows)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return r.scanRaces(rows)
+	var nextPageToken string
+	if pageSize > 0 && len(races) > int(pageSize) {
+		races = races[:pageSize]
+
+		nextPageToken, err = encodePageToken(races[len(races)-1], orderBy)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return races, nextPageToken, nil
 }
 
-func (r *racesRepo) applyFilter(query string, filter *racing.ListRacesRequestFilter) (string, []interface{}) {
+func (r *racesRepo) applyFilter(query string, filter *racing.ListRacesRequestFilter, cursor *pageCursor) (string, []interface{}) {
 	var (
 		clauses []string
 		args    []interface{}
+		orderBy *racing.OrderBy
 	)
 
-	if filter == nil {
-		return query, args
-	}
+	if filter != nil {
+		orderBy = filter.OrderBy
 
-	if len(filter.MeetingIds) > 0 {
-		clauses = append(clauses, "meeting_id IN ("+strings.Repeat("?,", len(filter.MeetingIds)-1)+"?)")
+		if len(filter.MeetingIds) > 0 {
+			clauses = append(clauses, "meeting_id IN ("+strings.Repeat("?,", len(filter.MeetingIds)-1)+"?)")
+
+			for _, meetingID := range filter.MeetingIds {
+				args = append(args, meetingID)
+			}
+		}
 
-		for _, meetingID := range filter.MeetingIds {
-			args = append(args, meetingID)
+		if filter.Visible != nil && *filter.Visible == true {
+			clauses = append(clauses, "visible = 1")
+		} else if filter.Visible != nil && *filter.Visible == false {
+			clauses = append(clauses, "visible = 0")
+		}
+
+		if len(filter.Statuses) > 0 {
+			clauses = append(clauses, raceStatus+" IN ("+strings.Repeat("?,", len(filter.Statuses)-1)+"?)")
+
+			for _, statusFilter := range filter.Statuses {
+				args = append(args, statusFilter)
+			}
+		}
+
+		if filter.StartAfter != nil {
+			clauses = append(clauses, startTimeKey+" >= julianday(?)")
+			args = append(args, filter.StartAfter.AsTime().Format(time.RFC3339Nano))
+		}
+
+		if filter.StartBefore != nil {
+			clauses = append(clauses, startTimeKey+" < julianday(?)")
+			args = append(args, filter.StartBefore.AsTime().Format(time.RFC3339Nano))
 		}
 	}
 
-	if filter.Visible != nil && *filter.Visible == true {
-		clauses = append(clauses, "visible = 1")
-	} else if filter.Visible != nil && *filter.Visible == false {
-		clauses = append(clauses, "visible = 0")
+	// Continue after the last race of the previous page, following the same ordering as below.
+	if cursor != nil {
+		lastStart := cursor.AdvertisedStartTime.Format(time.RFC3339Nano)
+
+		switch {
+		case orderBy != nil && *orderBy == racing.OrderBy_ASC:
+			clauses = append(clauses, "("+startTimeKey+" > julianday(?) OR ("+startTimeKey+" = julianday(?) AND id > ?))")
+			args = append(args, lastStart, lastStart, cursor.ID)
+		case orderBy != nil && *orderBy == racing.OrderBy_DESC:
+			clauses = append(clauses, "("+startTimeKey+" < julianday(?) OR ("+startTimeKey+" = julianday(?) AND id < ?))")
+			args = append(args, lastStart, lastStart, cursor.ID)
+		default:
+			clauses = append(clauses, "id > ?")
+			args = append(args, cursor.ID)
+		}
 	}
 
 	if len(clauses) != 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
 	}
 
-	if filter.OrderBy != nil && *filter.OrderBy == racing.OrderBy_ASC {
-		query += " ORDER BY advertised_start_time ASC"
-	} else if filter.OrderBy != nil && *filter.OrderBy == racing.OrderBy_DESC {
-		query += " ORDER BY advertised_start_time DESC"
+	// id breaks ties between races with the same advertised start time, so pages are stable.
+	if orderBy != nil && *orderBy == racing.OrderBy_ASC {
+		query += " ORDER BY " + startTimeKey + " ASC, id ASC"
+	} else if orderBy != nil && *orderBy == racing.OrderBy_DESC {
+		query += " ORDER BY " + startTimeKey + " DESC, id DESC"
+	} else {
+		query += " ORDER BY id ASC"
 	}
 
 	return query, args
@@ -108,13 +212,12 @@ func (m *racesRepo) scanRaces(
 	rows *sql.Rows,
 ) ([]*racing.Race, error) {
 	var races []*racing.Race
-	todayTime := time.Now()
 
 	for rows.Next() {
 		var race racing.Race
 		var advertisedStart time.Time
 
-		if err := rows.Scan(&race.Id, &race.MeetingId, &race.Name, &race.Number, &race.Visible, &advertisedStart); err != nil {
+		if err := rows.Scan(&race.Id, &race.MeetingId, &race.Name, &race.Number, &race.Visible, &advertisedStart, &race.Status, &race.Version); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, nil
 			}
@@ -129,32 +232,30 @@ func (m *racesRepo) scanRaces(
 
 		race.AdvertisedStartTime = ts
 
-		if todayTime.After(advertisedStart) {
-			race.Status = racing.Status_CLOSED
-		}
-
 		races = append(races, &race)
 	}
 
 	return races, nil
 }
 
-func (r *racesRepo) Get(id int64) (*racing.Race, error) {
+func (r *racesRepo) Get(ctx context.Context, id int64) (*racing.Race, error) {
+	ctx, done := observeQuery(ctx, "races", "Get")
+	defer done()
+
 	var (
 		err             error
 		query           string
 		race            racing.Race
 		advertisedStart time.Time
 	)
-	todayTime := time.Now()
 
 	// fetch query to get a race
 	query = getRaceQueries()[raceById]
 
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	// casting to racing.Race
-	if err := row.Scan(&race.Id, &race.MeetingId, &race.Name, &race.Number, &race.Visible, &advertisedStart); err != nil {
+	if err := row.Scan(&race.Id, &race.MeetingId, &race.Name, &race.Number, &race.Visible, &advertisedStart, &race.Status, &race.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, status.Error(codes.NotFound, "Race not found")
 		}
@@ -169,9 +270,163 @@ func (r *racesRepo) Get(id int64) (*racing.Race, error) {
 
 	race.AdvertisedStartTime = ts
 
-	if todayTime.After(advertisedStart) {
-		race.Status = racing.Status_CLOSED
+	return &race, nil
+}
+
+func (r *racesRepo) UpdateStatus(ctx context.Context, id int64, from racing.Status, to racing.Status) (*racing.Race, error) {
+	ctx, done := observeQuery(ctx, "races", "UpdateStatus")
+	defer done()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	return &race, nil
+	res, err := tx.ExecContext(ctx, getRaceQueries()[raceUpdateStatus], to, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	// Either the race is gone or its status moved on since it was read.
+	if updated == 0 {
+		if _, err := r.Get(ctx, id); err != nil {
+			return nil, err
+		}
+
+		return nil, status.Error(codes.Aborted, "Race status was changed concurrently")
+	}
+
+	// An abandoned race was never run, so any interim results no longer stand.
+	if to == racing.Status_ABANDONED {
+		if _, err := tx.ExecContext(ctx, getResultQueries()[resultsDeleteByRace], id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(ctx, id)
+}
+
+func (r *racesRepo) Create(ctx context.Context, race *racing.Race) (*racing.Race, error) {
+	ctx, done := observeQuery(ctx, "races", "Create")
+	defer done()
+
+	res, err := r.db.ExecContext(ctx, getRaceQueries()[raceInsert],
+		race.MeetingId,
+		race.Name,
+		race.Number,
+		race.Visible,
+		race.AdvertisedStartTime.AsTime().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Get(ctx, id)
+}
+
+func (r *racesRepo) Update(ctx context.Context, race *racing.Race) (*racing.Race, error) {
+	ctx, done := observeQuery(ctx, "races", "Update")
+	defer done()
+
+	res, err := r.db.ExecContext(ctx, getRaceQueries()[raceUpdate],
+		race.MeetingId,
+		race.Name,
+		race.Number,
+		race.Visible,
+		race.AdvertisedStartTime.AsTime().UTC().Format(time.RFC3339),
+		race.Id,
+		race.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.checkVersioned(ctx, res, race.Id); err != nil {
+		return nil, err
+	}
+
+	return r.Get(ctx, race.Id)
+}
+
+func (r *racesRepo) Delete(ctx context.Context, id int64, version int64) error {
+	ctx, done := observeQuery(ctx, "races", "Delete")
+	defer done()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, getRaceQueries()[raceDelete], id, version)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkVersioned(ctx, res, id); err != nil {
+		return err
+	}
+
+	// Prices are found through the runners, so they go before them.
+	for _, query := range []string{
+		getResultQueries()[resultsDeleteByRace],
+		getPriceQueries()[pricesDeleteByRace],
+		getRunnerQueries()[runnersDeleteByRace],
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *racesRepo) NextClose(ctx context.Context) (time.Time, bool, error) {
+	ctx, done := observeQuery(ctx, "races", "NextClose")
+	defer done()
+
+	var seconds sql.NullFloat64
+	if err := r.db.QueryRowContext(ctx, getRaceQueries()[raceNextClose]).Scan(&seconds); err != nil {
+		return time.Time{}, false, err
+	}
+
+	if !seconds.Valid {
+		return time.Time{}, false, nil
+	}
+
+	return time.Unix(0, int64(seconds.Float64*float64(time.Second))), true, nil
+}
+
+// checkVersioned reports why a write guarded by the race's version changed nothing.
+func (r *racesRepo) checkVersioned(ctx context.Context, res sql.Result, id int64) error {
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// Either the race is gone or it changed since it was read.
+	if updated == 0 {
+		if _, err := r.Get(ctx, id); err != nil {
+			return err
+		}
+
+		return status.Error(codes.Aborted, "Race was changed concurrently")
+	}
+
+	return nil
 }
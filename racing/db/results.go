@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResultsRepo provides repository access to race results.
+type ResultsRepo interface {
+	// Init will initialise our results repository.
+	Init() error
+
+	// List will return results of resulted races, ordered by race and position.
+	List(ctx context.Context, filter *racing.ListResultsRequestFilter) ([]*racing.Result, error)
+
+	// ListByRace will return the results of one race, ordered by position.
+	ListByRace(ctx context.Context, raceID int64) ([]*racing.Result, error)
+
+	// Set will replace the results of a race and move it from status from to status to,
+	// all in one transaction.
+	Set(ctx context.Context, raceID int64, results []*racing.Result, from racing.Status, to racing.Status) error
+}
+
+type resultsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewResultsRepo creates a new results repository.
+func NewResultsRepo(db *sql.DB) ResultsRepo {
+	return &resultsRepo{db: db}
+}
+
+// Init prepares the results repository.
+func (r *resultsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *resultsRepo) List(ctx context.Context, filter *racing.ListResultsRequestFilter) ([]*racing.Result, error) {
+	ctx, done := observeQuery(ctx, "results", "List")
+	defer done()
+
+	var (
+		err   error
+		query string
+		args  []interface{}
+	)
+
+	query = getResultQueries()[resultsList]
+
+	query, args = r.applyFilter(query, filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanResults(rows)
+}
+
+func (r *resultsRepo) applyFilter(query string, filter *racing.ListResultsRequestFilter) (string, []interface{}) {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+
+	if filter != nil {
+		if len(filter.MeetingIds) > 0 {
+			clauses = append(clauses, "races.meeting_id IN ("+strings.Repeat("?,", len(filter.MeetingIds)-1)+"?)")
+
+			for _, meetingID := range filter.MeetingIds {
+				args = append(args, meetingID)
+			}
+		}
+
+		if filter.Date != "" {
+			clauses = append(clauses, "meetings.date = ?")
+			args = append(args, filter.Date)
+		}
+
+		if filter.FinalOnly {
+			clauses = append(clauses, raceStatus+" = ?")
+			args = append(args, racing.Status_FINAL)
+		}
+	}
+
+	if len(clauses) != 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	query += " ORDER BY res.race_id ASC, res.position ASC, res.runner_id ASC"
+
+	return query, args
+}
+
+func (r *resultsRepo) ListByRace(ctx context.Context, raceID int64) ([]*racing.Result, error) {
+	ctx, done := observeQuery(ctx, "results", "ListByRace")
+	defer done()
+
+	rows, err := r.db.QueryContext(ctx, getResultQueries()[resultsByRace], raceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanResults(rows)
+}
+
+func (r *resultsRepo) Set(ctx context.Context, raceID int64, results []*racing.Result, from racing.Status, to racing.Status) error {
+	ctx, done := observeQuery(ctx, "results", "Set")
+	defer done()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, getRaceQueries()[raceUpdateStatus], to, raceID, from)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return status.Error(codes.Aborted, "Race status was changed concurrently")
+	}
+
+	if _, err := tx.ExecContext(ctx, getResultQueries()[resultsDeleteByRace], raceID); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if _, err := tx.ExecContext(ctx, getResultQueries()[resultInsert], raceID, result.RunnerId, result.Position, result.WinDividend, result.PlaceDividend); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *resultsRepo) scanResults(
+	rows *sql.Rows,
+) ([]*racing.Result, error) {
+	var results []*racing.Result
+
+	for rows.Next() {
+		var result racing.Result
+
+		if err := rows.Scan(&result.RaceId, &result.RunnerId, &result.Position, &result.WinDividend, &result.PlaceDividend); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		results = append(results, &result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RunnersRepo provides repository access to runners.
+type RunnersRepo interface {
+	// Init will initialise our runners repository.
+	Init() error
+
+	// List will return the runners of a race, ordered by number.
+	List(ctx context.Context, raceID int64) ([]*racing.Runner, error)
+
+	// Get will return one runner.
+	Get(ctx context.Context, id int64) (*racing.Runner, error)
+}
+
+type runnersRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewRunnersRepo creates a new runners repository.
+func NewRunnersRepo(db *sql.DB) RunnersRepo {
+	return &runnersRepo{db: db}
+}
+
+// Init prepares the runner repository dummy data.
+func (r *runnersRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with some dummy runners.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *runnersRepo) List(ctx context.Context, raceID int64) ([]*racing.Runner, error) {
+	ctx, done := observeQuery(ctx, "runners", "List")
+	defer done()
+
+	rows, err := r.db.QueryContext(ctx, getRunnerQueries()[runnersByRace], raceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanRunners(rows)
+}
+
+func (r *runnersRepo) scanRunners(
+	rows *sql.Rows,
+) ([]*racing.Runner, error) {
+	var runners []*racing.Runner
+
+	for rows.Next() {
+		var runner racing.Runner
+
+		if err := rows.Scan(&runner.Id, &runner.RaceId, &runner.Number, &runner.Name, &runner.Barrier, &runner.Jockey, &runner.Trainer, &runner.Weight, &runner.Scratched); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		runners = append(runners, &runner)
+	}
+
+	return runners, nil
+}
+
+func (r *runnersRepo) Get(ctx context.Context, id int64) (*racing.Runner, error) {
+	ctx, done := observeQuery(ctx, "runners", "Get")
+	defer done()
+
+	var runner racing.Runner
+
+	row := r.db.QueryRowContext(ctx, getRunnerQueries()[runnerById], id)
+
+	if err := row.Scan(&runner.Id, &runner.RaceId, &runner.Number, &runner.Name, &runner.Barrier, &runner.Jockey, &runner.Trainer, &runner.Weight, &runner.Scratched); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "Runner not found")
+		}
+
+		return nil, err
+	}
+
+	return &runner, nil
+}
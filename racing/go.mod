@@ -3,10 +3,17 @@ module git.neds.sh/matty/entain/racing
 go 1.16
 
 require (
+	git.neds.sh/matty/entain/pkg v0.0.0-00010101000000-000000000000
 	github.com/bufbuild/buf v0.37.0 // indirect
+	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/golang/protobuf v1.5.3
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.15.2
 	github.com/mattn/go-sqlite3 v1.14.14
+	github.com/prometheus/client_golang v1.15.1
+	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.40.0
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
 	golang.org/x/net v0.8.0
 	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1
 	google.golang.org/grpc v1.54.0
@@ -14,3 +21,5 @@ require (
 	google.golang.org/protobuf v1.30.0
 	syreclabs.com/go/faker v1.2.3
 )
+
+replace git.neds.sh/matty/entain/pkg => ../pkg
@@ -1,19 +1,41 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"git.neds.sh/matty/entain/pkg/server"
+	"git.neds.sh/matty/entain/pkg/tracing"
 	"git.neds.sh/matty/entain/racing/db"
 	"git.neds.sh/matty/entain/racing/proto/racing"
 	"git.neds.sh/matty/entain/racing/service"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 var (
-	grpcEndpoint = flag.String("grpc-endpoint", "localhost:9000", "gRPC server endpoint")
+	grpcEndpoint    = flag.String("grpc-endpoint", "localhost:9000", "gRPC server endpoint")
+	otlpEndpoint    = flag.String("otlp-endpoint", "", "OTLP gRPC endpoint the trace spans are exported to, e.g. localhost:4317")
+	otlpInsecure    = flag.Bool("otlp-insecure", false, "Export the trace spans to the OTLP endpoint without TLS")
+	traceFile       = flag.String("trace-file", "", "File the trace spans are written to as JSON lines, - for stdout")
+	shutdownGrace   = flag.Duration("shutdown-grace-period", 15*time.Second, "Time in-flight calls have to finish once a SIGINT or SIGTERM is received")
+	jwksFile        = flag.String("jwks-file", "", "JWKS file holding the keys bearer tokens are signed with")
+	jwtKeyFile      = flag.String("jwt-key-file", "", "PEM public key or HMAC secret bearer tokens are signed with, instead of a JWKS file")
+	jwtIssuer       = flag.String("jwt-issuer", "", "Issuer bearer tokens must be issued by, if set")
+	jwtAudience     = flag.String("jwt-audience", "", "Audience bearer tokens must be issued for, if set")
+	metricsEndpoint = flag.String("metrics-endpoint", "localhost:9100", "Endpoint serving the Prometheus metrics on /metrics")
 )
 
 func main() {
@@ -25,6 +47,15 @@ func main() {
 }
 
 func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	flushTracing, err := tracing.Setup(ctx, "racing", *otlpEndpoint, *otlpInsecure, *traceFile)
+	if err != nil {
+		return err
+	}
+	defer flushTracing()
+
 	conn, err := net.Listen("tcp", ":9000")
 	if err != nil {
 		return err
@@ -34,26 +65,135 @@ func run() error {
 	if err != nil {
 		return err
 	}
+	defer racingDB.Close()
 
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
 	racesRepo := db.NewRacesRepo(racingDB)
-	if err := racesRepo.Init(); err != nil {
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+
+	// Write calls carry the caller's bearer token, checked against the keys the gateway uses
+	auth, err := service.NewAuthenticator(*jwksFile, *jwtKeyFile, *jwtIssuer, *jwtAudience)
+	if err != nil {
 		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	// Health checks are not traced, other calls are refused until the repos are initialised, and
+	// watches end as soon as the server shuts down
+	traced := otelgrpc.WithInterceptorFilter(filters.Not(filters.HealthCheck()))
+	gate := server.NewReadinessGate()
+	drainer := server.NewWatchDrainer()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(traced), service.UnaryMetricsInterceptor, gate.UnaryInterceptor, auth.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(traced), service.StreamMetricsInterceptor, gate.StreamInterceptor, auth.StreamInterceptor, drainer.StreamInterceptor),
+	)
 
 	racing.RegisterRacingServer(
 		grpcServer,
 		service.NewRacingService(
 			racesRepo,
+			meetingsRepo,
+			runnersRepo,
+			pricesRepo,
+			resultsRepo,
 		),
 	)
 
+	// The service is not ready until its database is open and its repos are initialised
+	healthServer := health.NewServer()
+	setServingStatus(healthServer, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	metricsServer := newMetricsServer(*metricsEndpoint)
+	go func() {
+		log.Printf("Metrics listening on: %s\n", *metricsEndpoint)
+
+		if err := metricsServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Printf("failed serving metrics: %s\n", err)
+		}
+	}()
+	defer metricsServer.Close()
+
 	log.Printf("gRPC server listening on: %s\n", *grpcEndpoint)
 
-	if err := grpcServer.Serve(conn); err != nil {
+	// Health checks are answered while the repos are initialised, other calls are refused
+	served := make(chan error, 1)
+	go func() {
+		served <- grpcServer.Serve(conn)
+	}()
+
+	if err := initRepos(racingDB, meetingsRepo, racesRepo, runnersRepo, pricesRepo, resultsRepo); err != nil {
+		grpcServer.Stop()
 		return err
 	}
 
+	gate.Ready()
+	setServingStatus(healthServer, healthpb.HealthCheckResponse_SERVING)
+	log.Printf("gRPC server ready\n")
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	// A second signal stops the server at once
+	stop()
+
+	log.Printf("Shutting down, waiting up to %s for in-flight calls\n", *shutdownGrace)
+
+	healthServer.Shutdown()
+	drainer.Drain()
+	stopGracefully(grpcServer, *shutdownGrace)
+
+	log.Printf("gRPC server stopped\n")
+
+	return nil
+}
+
+// stopGracefully stops accepting calls and waits for those in flight to finish, cutting the ones
+// left once the grace period is over.
+func stopGracefully(grpcServer *grpc.Server, grace time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(grace):
+		log.Printf("grace period over, cutting the calls left\n")
+		grpcServer.Stop()
+	}
+}
+
+// initRepos checks the database can be opened, then creates and seeds the tables of the repos.
+func initRepos(database *sql.DB, repos ...interface{ Init() error }) error {
+	if err := database.Ping(); err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		if err := repo.Init(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// setServingStatus reports the status for the server as a whole and for its service.
+func setServingStatus(healthServer *health.Server, status healthpb.HealthCheckResponse_ServingStatus) {
+	healthServer.SetServingStatus("", status)
+	healthServer.SetServingStatus(racing.Racing_ServiceDesc.ServiceName, status)
+}
+
+// newMetricsServer serves the Prometheus metrics on their own endpoint, away from the gRPC server.
+func newMetricsServer(endpoint string) *http.Server {
+	metrics := http.NewServeMux()
+	metrics.Handle("/metrics", promhttp.Handler())
+
+	return &http.Server{Addr: endpoint, Handler: metrics}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"time"
+
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// updatableRaceFields are the fields UpdateRace may change. The status has its own lifecycle,
+// see UpdateRaceStatus.
+var updatableRaceFields = []string{"meeting_id", "name", "number", "visible", "advertised_start_time"}
+
+func (s *racingService) CreateRace(ctx context.Context, in *racing.CreateRaceRequest) (*racing.Race, error) {
+	if in.Race == nil {
+		return nil, status.Error(codes.InvalidArgument, "race is required")
+	}
+
+	if err := s.validateRace(ctx, in.Race, true); err != nil {
+		return nil, err
+	}
+
+	race, err := s.racesRepo.Create(ctx, in.Race)
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Notify()
+
+	return race, nil
+}
+
+func (s *racingService) UpdateRace(ctx context.Context, in *racing.UpdateRaceRequest) (*racing.Race, error) {
+	if in.Race == nil {
+		return nil, status.Error(codes.InvalidArgument, "race is required")
+	}
+
+	paths, err := raceUpdatePaths(in.UpdateMask)
+	if err != nil {
+		return nil, err
+	}
+
+	race, err := s.racesRepo.Get(ctx, in.Race.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Race.Version != 0 && in.Race.Version != race.Version {
+		return nil, status.Errorf(codes.Aborted, "Race is at version %d, not %d", race.Version, in.Race.Version)
+	}
+
+	startTimeChanged := false
+	for _, path := range paths {
+		switch path {
+		case "meeting_id":
+			race.MeetingId = in.Race.MeetingId
+		case "name":
+			race.Name = in.Race.Name
+		case "number":
+			race.Number = in.Race.Number
+		case "visible":
+			race.Visible = in.Race.Visible
+		case "advertised_start_time":
+			race.AdvertisedStartTime = in.Race.AdvertisedStartTime
+			startTimeChanged = true
+		}
+	}
+
+	// A race that has already jumped can still be corrected, as long as its start time is not moved.
+	if err := s.validateRace(ctx, race, startTimeChanged); err != nil {
+		return nil, err
+	}
+
+	race, err = s.racesRepo.Update(ctx, race)
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Notify()
+
+	return race, nil
+}
+
+func (s *racingService) DeleteRace(ctx context.Context, in *racing.DeleteRaceRequest) (*emptypb.Empty, error) {
+	race, err := s.racesRepo.Get(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Version != 0 && in.Version != race.Version {
+		return nil, status.Errorf(codes.Aborted, "Race is at version %d, not %d", race.Version, in.Version)
+	}
+
+	if err := s.racesRepo.Delete(ctx, race.Id, race.Version); err != nil {
+		return nil, err
+	}
+
+	s.changes.Notify()
+
+	return &emptypb.Empty{}, nil
+}
+
+// validateRace checks the fields of a race about to be stored. The start time is only checked
+// to be in the future when checkStart is set.
+func (s *racingService) validateRace(ctx context.Context, race *racing.Race, checkStart bool) error {
+	if race.Name == "" {
+		return status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	if race.Number <= 0 {
+		return status.Error(codes.InvalidArgument, "number must be greater than 0")
+	}
+
+	if race.AdvertisedStartTime == nil || !race.AdvertisedStartTime.IsValid() {
+		return status.Error(codes.InvalidArgument, "advertised_start_time is required")
+	}
+
+	if checkStart && !race.AdvertisedStartTime.AsTime().After(time.Now()) {
+		return status.Error(codes.InvalidArgument, "advertised_start_time must be in the future")
+	}
+
+	if _, err := s.meetingsRepo.Get(ctx, race.MeetingId); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return status.Errorf(codes.InvalidArgument, "meeting %d does not exist", race.MeetingId)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// raceUpdatePaths returns the fields an UpdateRace mask names, or every updatable field for an
+// empty mask. id and version identify the race rather than change it, so they are skipped; the
+// gateway adds them to the mask when they are sent in a PATCH body.
+func raceUpdatePaths(mask *fieldmaskpb.FieldMask) ([]string, error) {
+	if len(mask.GetPaths()) == 0 {
+		return updatableRaceFields, nil
+	}
+
+	var paths []string
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case "id", "version":
+			continue
+		case "meeting_id", "name", "number", "visible", "advertised_start_time":
+			paths = append(paths, path)
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "%q cannot be updated", path)
+		}
+	}
+
+	return paths, nil
+}
@@ -1,43 +1,355 @@
 package service
 
 import (
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/notify"
 	"git.neds.sh/matty/entain/racing/db"
 	"git.neds.sh/matty/entain/racing/proto/racing"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// dateLayout is the format of the days meetings are held on.
+const dateLayout = "2006-01-02"
+
 type Racing interface {
 	// ListRaces will return a collection of races.
 	ListRaces(ctx context.Context, in *racing.ListRacesRequest) (*racing.ListRacesResponse, error)
 
 	// GetRace will return one race
 	GetRace(ctx context.Context, in *racing.GetRaceRequest) (*racing.Race, error)
+
+	// UpdateRaceStatus will move a race to a new status
+	UpdateRaceStatus(ctx context.Context, in *racing.UpdateRaceStatusRequest) (*racing.Race, error)
+
+	// ListMeetings will return a collection of meetings.
+	ListMeetings(ctx context.Context, in *racing.ListMeetingsRequest) (*racing.ListMeetingsResponse, error)
+
+	// GetMeeting will return one meeting
+	GetMeeting(ctx context.Context, in *racing.GetMeetingRequest) (*racing.Meeting, error)
+
+	// ListRunners will return the runners of a race
+	ListRunners(ctx context.Context, in *racing.ListRunnersRequest) (*racing.ListRunnersResponse, error)
+
+	// GetPrices will return the current prices of a race
+	GetPrices(ctx context.Context, in *racing.GetPricesRequest) (*racing.GetPricesResponse, error)
+
+	// ListPriceHistory will return every price of a runner
+	ListPriceHistory(ctx context.Context, in *racing.ListPriceHistoryRequest) (*racing.ListPriceHistoryResponse, error)
+
+	// UpdatePrice will offer a runner at a new price
+	UpdatePrice(ctx context.Context, in *racing.UpdatePriceRequest) (*racing.Price, error)
+
+	// SetResult will record the result of a race
+	SetResult(ctx context.Context, in *racing.SetResultRequest) (*racing.Race, error)
+
+	// ListResults will return a collection of results
+	ListResults(ctx context.Context, in *racing.ListResultsRequest) (*racing.ListResultsResponse, error)
+
+	// WatchRaces will stream changes to the races matching a filter
+	WatchRaces(in *racing.WatchRacesRequest, stream racing.Racing_WatchRacesServer) error
+
+	// WatchChanges will stream a signal each time races change
+	WatchChanges(in *racing.WatchChangesRequest, stream racing.Racing_WatchChangesServer) error
+
+	// CreateRace will add a race
+	CreateRace(ctx context.Context, in *racing.CreateRaceRequest) (*racing.Race, error)
+
+	// UpdateRace will change the fields of a race
+	UpdateRace(ctx context.Context, in *racing.UpdateRaceRequest) (*racing.Race, error)
+
+	// DeleteRace will remove a race
+	DeleteRace(ctx context.Context, in *racing.DeleteRaceRequest) (*emptypb.Empty, error)
 }
 
 // racingService implements the Racing interface.
 type racingService struct {
-	racesRepo db.RacesRepo
+	racesRepo    db.RacesRepo
+	meetingsRepo db.MeetingsRepo
+	runnersRepo  db.RunnersRepo
+	pricesRepo   db.PricesRepo
+	resultsRepo  db.ResultsRepo
+	changes      *notify.Notifier
 }
 
 // NewRacingService instantiates and returns a new racingService.
-func NewRacingService(racesRepo db.RacesRepo) Racing {
-	return &racingService{racesRepo}
+func NewRacingService(racesRepo db.RacesRepo, meetingsRepo db.MeetingsRepo, runnersRepo db.RunnersRepo, pricesRepo db.PricesRepo, resultsRepo db.ResultsRepo) Racing {
+	return &racingService{racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo, notify.New()}
 }
 
 func (s *racingService) ListRaces(ctx context.Context, in *racing.ListRacesRequest) (*racing.ListRacesResponse, error) {
-	races, err := s.racesRepo.List(in.Filter)
+	races, nextPageToken, err := s.racesRepo.List(ctx, in.Filter, in.PageSize, in.PageToken)
 	if err != nil {
 		return nil, err
 	}
 
-	return &racing.ListRacesResponse{Races: races}, nil
+	if in.IncludeMeetings {
+		if err := s.embedMeetings(ctx, races); err != nil {
+			return nil, err
+		}
+	}
+
+	return &racing.ListRacesResponse{Races: races, NextPageToken: nextPageToken}, nil
 }
 
 func (s *racingService) GetRace(ctx context.Context, in *racing.GetRaceRequest) (*racing.Race, error) {
-	race, err := s.racesRepo.Get(in.Id)
+	race, err := s.racesRepo.Get(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.IncludeRunners {
+		race.Runners, err = s.runnersRepo.List(ctx, race.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	race.Results, err = s.resultsRepo.ListByRace(ctx, race.Id)
 	if err != nil {
 		return nil, err
 	}
 
 	return race, nil
 }
+
+func (s *racingService) UpdateRaceStatus(ctx context.Context, in *racing.UpdateRaceStatusRequest) (*racing.Race, error) {
+	race, err := s.racesRepo.Get(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isResultStatus(in.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Race can only move to %s by setting its result", in.Status)
+	}
+
+	if !canTransition(race.Status, in.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Race cannot move from %s to %s", race.Status, in.Status)
+	}
+
+	race, err = s.racesRepo.UpdateStatus(ctx, in.Id, race.Status, in.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	s.changes.Notify()
+
+	return race, nil
+}
+
+func (s *racingService) ListMeetings(ctx context.Context, in *racing.ListMeetingsRequest) (*racing.ListMeetingsResponse, error) {
+	meetings, err := s.meetingsRepo.List(ctx, in.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &racing.ListMeetingsResponse{Meetings: meetings}, nil
+}
+
+func (s *racingService) GetMeeting(ctx context.Context, in *racing.GetMeetingRequest) (*racing.Meeting, error) {
+	return s.meetingsRepo.Get(ctx, in.Id)
+}
+
+func (s *racingService) ListRunners(ctx context.Context, in *racing.ListRunnersRequest) (*racing.ListRunnersResponse, error) {
+	// Unknown races are reported as not found rather than as an empty race card.
+	if _, err := s.racesRepo.Get(ctx, in.RaceId); err != nil {
+		return nil, err
+	}
+
+	runners, err := s.runnersRepo.List(ctx, in.RaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &racing.ListRunnersResponse{Runners: runners}, nil
+}
+
+func (s *racingService) GetPrices(ctx context.Context, in *racing.GetPricesRequest) (*racing.GetPricesResponse, error) {
+	race, err := s.racesRepo.Get(ctx, in.RaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.pricesRepo.ListCurrent(ctx, in.RaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &racing.GetPricesResponse{Prices: prices, Locked: race.Status != racing.Status_OPEN}, nil
+}
+
+func (s *racingService) ListPriceHistory(ctx context.Context, in *racing.ListPriceHistoryRequest) (*racing.ListPriceHistoryResponse, error) {
+	if _, err := s.runnersRepo.Get(ctx, in.RunnerId); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.pricesRepo.ListHistory(ctx, in.RunnerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &racing.ListPriceHistoryResponse{Prices: prices}, nil
+}
+
+func (s *racingService) UpdatePrice(ctx context.Context, in *racing.UpdatePriceRequest) (*racing.Price, error) {
+	if in.Win <= 1 || in.Place <= 1 {
+		return nil, status.Error(codes.InvalidArgument, "win and place prices must be greater than 1")
+	}
+
+	runner, err := s.runnersRepo.Get(ctx, in.RunnerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if runner.Scratched {
+		return nil, status.Error(codes.FailedPrecondition, "Scratched runners cannot be priced")
+	}
+
+	race, err := s.racesRepo.Get(ctx, runner.RaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prices lock as soon as the race leaves OPEN, whether it was moved on or closed by the clock.
+	if race.Status != racing.Status_OPEN {
+		return nil, status.Errorf(codes.FailedPrecondition, "Prices are locked as the race is %s", race.Status)
+	}
+
+	return s.pricesRepo.Insert(ctx, runner.Id, in.Win, in.Place, time.Now())
+}
+
+func (s *racingService) SetResult(ctx context.Context, in *racing.SetResultRequest) (*racing.Race, error) {
+	race, err := s.racesRepo.Get(ctx, in.RaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	to := racing.Status_INTERIM
+	if in.Final {
+		to = racing.Status_FINAL
+	}
+
+	if !canResult(race.Status, to) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Race cannot be resulted as %s while %s", to, race.Status)
+	}
+
+	runners, err := s.runnersRepo.List(ctx, race.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateResults(in.Results, runners); err != nil {
+		return nil, err
+	}
+
+	if err := s.resultsRepo.Set(ctx, race.Id, in.Results, race.Status, to); err != nil {
+		return nil, err
+	}
+
+	s.changes.Notify()
+
+	return s.GetRace(ctx, &racing.GetRaceRequest{Id: race.Id})
+}
+
+func (s *racingService) ListResults(ctx context.Context, in *racing.ListResultsRequest) (*racing.ListResultsResponse, error) {
+	if date := in.GetFilter().GetDate(); date != "" {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "date %q must be formatted as YYYY-MM-DD", date)
+		}
+	}
+
+	results, err := s.resultsRepo.List(ctx, in.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &racing.ListResultsResponse{Results: results}, nil
+}
+
+// validateResults checks placings are for unscratched runners of the race, each placed once,
+// and that positions follow dead heats: two runners sharing 1st are followed by 3rd, not 2nd.
+func validateResults(results []*racing.Result, runners []*racing.Runner) error {
+	if len(results) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one result is required")
+	}
+
+	inRace := make(map[int64]*racing.Runner, len(runners))
+	for _, runner := range runners {
+		inRace[runner.Id] = runner
+	}
+
+	placed := make(map[int64]bool, len(results))
+	ahead := make(map[int64]int64)
+	for _, result := range results {
+		runner, ok := inRace[result.RunnerId]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "runner %d is not in the race", result.RunnerId)
+		}
+		if runner.Scratched {
+			return status.Errorf(codes.InvalidArgument, "runner %d is scratched", result.RunnerId)
+		}
+		if placed[result.RunnerId] {
+			return status.Errorf(codes.InvalidArgument, "runner %d is placed more than once", result.RunnerId)
+		}
+		if result.Position < 1 {
+			return status.Errorf(codes.InvalidArgument, "runner %d has an invalid position %d", result.RunnerId, result.Position)
+		}
+		if result.WinDividend < 0 || result.PlaceDividend < 0 {
+			return status.Errorf(codes.InvalidArgument, "runner %d has a negative dividend", result.RunnerId)
+		}
+
+		placed[result.RunnerId] = true
+		ahead[result.Position]++
+	}
+
+	// Each position must be one more than the number of runners finishing ahead of it.
+	for _, result := range results {
+		var finishedAhead int64
+		for position, count := range ahead {
+			if position < result.Position {
+				finishedAhead += count
+			}
+		}
+
+		if result.Position != finishedAhead+1 {
+			return status.Errorf(codes.InvalidArgument, "position %d does not follow the positions ahead of it", result.Position)
+		}
+	}
+
+	return nil
+}
+
+// embedMeetings sets the meeting of each race, fetching all of them in a single query.
+func (s *racingService) embedMeetings(ctx context.Context, races []*racing.Race) error {
+	if len(races) == 0 {
+		return nil
+	}
+
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, race := range races {
+		if !seen[race.MeetingId] {
+			seen[race.MeetingId] = true
+			ids = append(ids, race.MeetingId)
+		}
+	}
+
+	meetings, err := s.meetingsRepo.List(ctx, &racing.ListMeetingsRequestFilter{Ids: ids})
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*racing.Meeting, len(meetings))
+	for _, meeting := range meetings {
+		byID[meeting.Id] = meeting
+	}
+
+	for _, race := range races {
+		race.Meeting = byID[race.MeetingId]
+	}
+
+	return nil
+}
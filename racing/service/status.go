@@ -0,0 +1,45 @@
+package service
+
+import "git.neds.sh/matty/entain/racing/proto/racing"
+
+// statusTransitions lists the statuses a race may move to from each status.
+// FINAL and ABANDONED are terminal, so they have no entry.
+var statusTransitions = map[racing.Status][]racing.Status{
+	racing.Status_OPEN:      {racing.Status_CLOSED, racing.Status_SUSPENDED, racing.Status_ABANDONED, racing.Status_POSTPONED},
+	racing.Status_SUSPENDED: {racing.Status_OPEN, racing.Status_CLOSED, racing.Status_ABANDONED, racing.Status_POSTPONED},
+	racing.Status_POSTPONED: {racing.Status_OPEN, racing.Status_ABANDONED},
+	racing.Status_CLOSED:    {racing.Status_INTERIM, racing.Status_ABANDONED},
+	racing.Status_INTERIM:   {racing.Status_FINAL, racing.Status_ABANDONED},
+}
+
+// canTransition reports whether a race may move from one status to another.
+func canTransition(from, to racing.Status) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isResultStatus reports whether a race only reaches the status by being resulted, since a race
+// moved to INTERIM or FINAL without placings would be resulted with nothing.
+func isResultStatus(to racing.Status) bool {
+	return to == racing.Status_INTERIM || to == racing.Status_FINAL
+}
+
+// canResult reports whether a race may be resulted as INTERIM or FINAL from its current status.
+// Interim results may be amended, and a CLOSED race may be declared FINAL straight away, going
+// through INTERIM on the way.
+func canResult(from, to racing.Status) bool {
+	if from == racing.Status_INTERIM && to == racing.Status_INTERIM {
+		return true
+	}
+
+	if canTransition(from, to) {
+		return true
+	}
+
+	return to == racing.Status_FINAL && canTransition(from, racing.Status_INTERIM)
+}
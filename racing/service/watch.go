@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchPollInterval is how often watchers re-read their races. Besides picking up changes made
+// outside this service, it is what notices races crossing their advertised start time, as their
+// status is derived from the clock.
+var watchPollInterval = time.Second
+
+// closeDelay is how long after a race's advertised start time WatchChanges reports it closed,
+// so that the race reads as CLOSED by then.
+const closeDelay = 50 * time.Millisecond
+
+// WatchRaces sends the races matching the filter as CREATED events, then streams an event each
+// time one of them changes until the client goes away. A race that stops matching the filter,
+// for instance because its status moved on, is reported as DELETED.
+func (s *racingService) WatchRaces(in *racing.WatchRacesRequest, stream racing.Racing_WatchRacesServer) error {
+	ctx := stream.Context()
+
+	changed, unsubscribe := s.changes.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var snapshot map[int64]*racing.Race
+
+	for {
+		races, _, err := s.racesRepo.List(ctx, in.Filter, 0, "")
+		if err != nil {
+			return err
+		}
+
+		next := make(map[int64]*racing.Race, len(races))
+		for _, race := range races {
+			next[race.Id] = race
+		}
+
+		for _, event := range diffRaces(snapshot, next, time.Now()) {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+
+		snapshot = next
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchChanges sends a signal once it is watching, then again each time this service changes a
+// race or a race closes with the clock, until the client goes away. Unlike WatchRaces it does not
+// read the races, so it costs nothing while nothing changes. Changes made outside this service
+// are not signalled.
+func (s *racingService) WatchChanges(in *racing.WatchChangesRequest, stream racing.Racing_WatchChangesServer) error {
+	ctx := stream.Context()
+
+	changed, unsubscribe := s.changes.Subscribe()
+	defer unsubscribe()
+
+	for {
+		if err := stream.Send(&emptypb.Empty{}); err != nil {
+			return err
+		}
+
+		ok, err := s.waitForChange(ctx, changed)
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// waitForChange blocks until races change or the next race closes with the clock. It returns
+// false once ctx is done.
+func (s *racingService) waitForChange(ctx context.Context, changed <-chan struct{}) (bool, error) {
+	closes, ok, err := s.racesRepo.NextClose(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var closed <-chan time.Time
+	if ok {
+		timer := time.NewTimer(time.Until(closes) + closeDelay)
+		defer timer.Stop()
+
+		closed = timer.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return false, nil
+	case <-changed:
+	case <-closed:
+	}
+
+	return true, nil
+}
+
+// diffRaces compares two snapshots of the watched races, keyed by id, and returns the events
+// turning the previous one into the next one, ordered by race id.
+func diffRaces(prev, next map[int64]*racing.Race, now time.Time) []*racing.RaceEvent {
+	var (
+		events []*racing.RaceEvent
+		ids    []int64
+	)
+
+	for id := range prev {
+		ids = append(ids, id)
+	}
+	for id := range next {
+		if _, ok := prev[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	ts := timestamppb.New(now)
+
+	for _, id := range ids {
+		before, existed := prev[id]
+		after, exists := next[id]
+
+		switch {
+		case !existed && exists:
+			events = append(events, &racing.RaceEvent{Type: racing.RaceEventType_CREATED, Race: after, Timestamp: ts})
+		case existed && !exists:
+			events = append(events, &racing.RaceEvent{Type: racing.RaceEventType_DELETED, Race: before, Timestamp: ts})
+		case existed && exists && !proto.Equal(before, after):
+			events = append(events, &racing.RaceEvent{Type: racing.RaceEventType_UPDATED, Race: after, Timestamp: ts})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,240 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCreateRace_Validation(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer racingDB.Close()
+
+	insertTestMeetings(racingDB, t)
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	ctx := context.Background()
+	future := timestamppb.New(time.Now().Add(time.Hour).Truncate(time.Second))
+
+	// Invalid races are rejected
+	for _, race := range []*racing.Race{
+		nil,
+		{MeetingId: 1, Name: "Test Race", Number: 0, AdvertisedStartTime: future},
+		{MeetingId: 99, Name: "Test Race", Number: 1, AdvertisedStartTime: future},
+		{MeetingId: 1, Name: "Test Race", Number: 1, AdvertisedStartTime: timestamppb.New(time.Now().Add(-time.Hour))},
+		{MeetingId: 1, Name: "Test Race", Number: 1},
+		{MeetingId: 1, Number: 1, AdvertisedStartTime: future},
+	} {
+		_, err = racingService.CreateRace(ctx, &racing.CreateRaceRequest{Race: race})
+		if grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected error code %v for %v but got %v", codes.InvalidArgument, race, grpc.Code(err))
+		}
+	}
+
+	// A valid race is stored with a new id, OPEN and at version 1
+	resp, err := racingService.CreateRace(ctx, &racing.CreateRaceRequest{Race: &racing.Race{
+		Id:                  42,
+		MeetingId:           1,
+		Name:                "Test Race",
+		Number:              3,
+		Visible:             true,
+		AdvertisedStartTime: future,
+		Status:              racing.Status_FINAL,
+		Version:             7,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Id != 1 || resp.Status != racing.Status_OPEN || resp.Version != 1 {
+		t.Errorf("Expected race 1, OPEN at version 1, but got race %d, %v at version %d", resp.Id, resp.Status, resp.Version)
+	}
+
+	got, err := racingService.GetRace(ctx, &racing.GetRaceRequest{Id: resp.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "Test Race" || got.Number != 3 || !got.Visible || !got.AdvertisedStartTime.AsTime().Equal(future.AsTime()) {
+		t.Errorf("Race was not stored as created, got %v", got)
+	}
+}
+
+func TestUpdateRace_FieldMaskAndVersion(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer racingDB.Close()
+
+	insertTestMeetings(racingDB, t)
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Race 1 has already been run
+	timeTest, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest),
+	}, racingDB, t)
+
+	ctx := context.Background()
+
+	// Only the fields in the mask change, and a past race can still be corrected
+	resp, err := racingService.UpdateRace(ctx, &racing.UpdateRaceRequest{
+		Race:       &racing.Race{Id: 1, Name: "Renamed Race", Number: 9, Version: 1},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "version"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Name != "Renamed Race" || resp.Number != 1 || resp.MeetingId != 1 || !resp.Visible || resp.Version != 2 {
+		t.Errorf("Expected only the name to change and version 2, got %v", resp)
+	}
+
+	// A stale version is rejected
+	_, err = racingService.UpdateRace(ctx, &racing.UpdateRaceRequest{
+		Race:       &racing.Race{Id: 1, Name: "Stale Race", Version: 1},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	if grpc.Code(err) != codes.Aborted {
+		t.Errorf("Expected error code %v but got %v", codes.Aborted, grpc.Code(err))
+	}
+
+	// Status changes bump the version too
+	_, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 1, Status: racing.Status_ABANDONED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = racingService.UpdateRace(ctx, &racing.UpdateRaceRequest{
+		Race:       &racing.Race{Id: 1, Name: "Stale Race", Version: 2},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	if grpc.Code(err) != codes.Aborted {
+		t.Errorf("Expected error code %v but got %v", codes.Aborted, grpc.Code(err))
+	}
+
+	// Fields outside the mask's reach, moving the start time into the past, unknown meetings
+	// and unknown races are rejected
+	for _, tc := range []struct {
+		in   *racing.UpdateRaceRequest
+		code codes.Code
+	}{
+		{&racing.UpdateRaceRequest{Race: &racing.Race{Id: 1, Status: racing.Status_OPEN}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}}}, codes.InvalidArgument},
+		{&racing.UpdateRaceRequest{Race: &racing.Race{Id: 1, AdvertisedStartTime: timestamppb.New(timeTest)}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"advertised_start_time"}}}, codes.InvalidArgument},
+		{&racing.UpdateRaceRequest{Race: &racing.Race{Id: 1, MeetingId: 99}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"meeting_id"}}}, codes.InvalidArgument},
+		{&racing.UpdateRaceRequest{Race: &racing.Race{Id: 2, Name: "Test Race 2"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}}}, codes.NotFound},
+	} {
+		_, err = racingService.UpdateRace(ctx, tc.in)
+		if grpc.Code(err) != tc.code {
+			t.Errorf("Expected error code %v for %v but got %v", tc.code, tc.in, grpc.Code(err))
+		}
+	}
+
+	// An empty mask updates every field, here postponing the race to a later meeting
+	future := timestamppb.New(time.Now().Add(24 * time.Hour).Truncate(time.Second))
+	resp, err = racingService.UpdateRace(ctx, &racing.UpdateRaceRequest{
+		Race: &racing.Race{Id: 1, MeetingId: 2, Name: "Postponed Race", Number: 4, AdvertisedStartTime: future},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.MeetingId != 2 || resp.Name != "Postponed Race" || resp.Number != 4 || resp.Visible || !resp.AdvertisedStartTime.AsTime().Equal(future.AsTime()) {
+		t.Errorf("Expected every field to change, got %v", resp)
+	}
+}
+
+func TestDeleteRace_Cascade(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer racingDB.Close()
+
+	insertTestRaceCard(racingDB, t)
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	ctx := context.Background()
+
+	// A stale version is rejected
+	_, err = racingService.DeleteRace(ctx, &racing.DeleteRaceRequest{Id: 1, Version: 2})
+	if grpc.Code(err) != codes.Aborted {
+		t.Errorf("Expected error code %v but got %v", codes.Aborted, grpc.Code(err))
+	}
+
+	_, err = racingService.DeleteRace(ctx, &racing.DeleteRaceRequest{Id: 1, Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The race and its runners are gone, the other race is untouched
+	_, err = racingService.GetRace(ctx, &racing.GetRaceRequest{Id: 1})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+
+	var runners int
+	if err := racingDB.QueryRow("SELECT COUNT(*) FROM runners WHERE race_id = 1").Scan(&runners); err != nil {
+		t.Fatal(err)
+	}
+	if runners != 0 {
+		t.Errorf("Expected the runners of race 1 to be deleted, %d remain", runners)
+	}
+
+	resp, err := racingService.ListRunners(ctx, &racing.ListRunnersRequest{RaceId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Runners) != 1 {
+		t.Errorf("Expected race 2 to keep its runner, got %v", resp.Runners)
+	}
+
+	// Deleting it again reports it as not found
+	_, err = racingService.DeleteRace(ctx, &racing.DeleteRaceRequest{Id: 1})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/auth"
+	"git.neds.sh/matty/entain/racing/service"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// testSecret is the HMAC secret the test tokens are signed with.
+var testSecret = []byte("a secret only the issuer and the services know")
+
+// newTestAuthenticator verifies tokens signed with testSecret.
+func newTestAuthenticator(t *testing.T) *auth.Authorizer {
+	t.Helper()
+
+	keyFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(keyFile, testSecret, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := service.NewAuthenticator("", keyFile, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return authenticator
+}
+
+// bearer issues a token for the role signed with key, and sends it as the caller's authorization.
+func bearer(t *testing.T, key []byte, role string) context.Context {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "jane",
+		"role": role,
+		"exp":  time.Now().Add(time.Minute).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestUnaryAuthInterceptor_Roles(t *testing.T) {
+	auth := newTestAuthenticator(t)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "called", nil
+	}
+
+	for _, c := range []struct {
+		ctx      context.Context
+		method   string
+		expected codes.Code
+	}{
+		{context.Background(), "/racing.Racing/ListRaces", codes.OK},
+		{context.Background(), "/racing.Racing/UpdatePrice", codes.Unauthenticated},
+		{bearer(t, testSecret, "public"), "/racing.Racing/SetResult", codes.PermissionDenied},
+		{bearer(t, testSecret, "trader"), "/racing.Racing/SetResult", codes.OK},
+		{bearer(t, testSecret, "trader"), "/racing.Racing/DeleteRace", codes.PermissionDenied},
+		{bearer(t, testSecret, "admin"), "/racing.Racing/DeleteRace", codes.OK},
+		{bearer(t, testSecret, "admin"), "/racing.Racing/UpdateRaceStatus", codes.OK},
+		{bearer(t, testSecret, "owner"), "/racing.Racing/UpdateRaceStatus", codes.Unauthenticated},
+	} {
+		resp, err := auth.UnaryInterceptor(c.ctx, nil, &grpc.UnaryServerInfo{FullMethod: c.method}, handler)
+		if grpc.Code(err) != c.expected {
+			t.Errorf("Expected error code %v calling %s but got %v", c.expected, c.method, grpc.Code(err))
+		}
+		if err == nil && resp != "called" {
+			t.Errorf("Expected %s to reach the service", c.method)
+		}
+	}
+}
+
+func TestUnaryAuthInterceptor_RejectsForgedRoles(t *testing.T) {
+	auth := newTestAuthenticator(t)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "called", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/racing.Racing/DeleteRace"}
+
+	// A role claimed in metadata, as the gateway used to send it, is not trusted
+	forged := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-auth-role", "admin", "x-auth-subject", "ops"))
+	if _, err := auth.UnaryInterceptor(forged, nil, info, handler); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected error code %v for a forged role but got %v", codes.Unauthenticated, grpc.Code(err))
+	}
+
+	// Nor is a token the caller signed itself
+	if _, err := auth.UnaryInterceptor(bearer(t, []byte("another secret"), "admin"), nil, info, handler); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected error code %v for a self-signed token but got %v", codes.Unauthenticated, grpc.Code(err))
+	}
+
+	// Without a key no token is accepted
+	noKey, err := service.NewAuthenticator("", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := noKey.UnaryInterceptor(bearer(t, testSecret, "admin"), nil, info, handler); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected error code %v without a key but got %v", codes.Unauthenticated, grpc.Code(err))
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/server"
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestWatchDrainer_EndsWatches(t *testing.T) {
+	racingDB, err := NewTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer racingDB.Close()
+
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour)),
+	}, racingDB, t)
+
+	drainer := server.NewWatchDrainer()
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(grpc.StreamInterceptor(drainer.StreamInterceptor))
+	racing.RegisterRacingServer(grpcServer, racingService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := racing.NewRacingClient(conn).WatchRaces(ctx, &racing.WatchRacesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once draining the watch ends, so a graceful stop does not wait for it
+	drainer.Drain()
+
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected error code %v but got %v", codes.Unavailable, err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the server to stop without waiting for the watch")
+	}
+}
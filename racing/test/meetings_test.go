@@ -0,0 +1,223 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func InsertNewMeeting(meeting *racing.Meeting, r *sql.DB, t *testing.T) {
+	_, err := r.Exec(getRaceQueriesForTest()[insertNewMeeting],
+		&meeting.Id,
+		&meeting.Venue,
+		&meeting.Country,
+		&meeting.RaceType,
+		&meeting.Date,
+	)
+
+	if err != nil {
+		t.Fatalf("Failed to insert meeting record: %v", err)
+	}
+}
+
+// insertTestMeetings seeds the meetings used by the tests below.
+func insertTestMeetings(racingDB *sql.DB, t *testing.T) {
+	InsertNewMeeting(&racing.Meeting{
+		Id:       1,
+		Venue:    "Flemington",
+		Country:  "AUS",
+		RaceType: racing.RaceType_THOROUGHBRED,
+		Date:     "2000-04-05",
+	}, racingDB, t)
+	InsertNewMeeting(&racing.Meeting{
+		Id:       2,
+		Venue:    "The Meadows",
+		Country:  "AUS",
+		RaceType: racing.RaceType_GREYHOUND,
+		Date:     "2000-04-05",
+	}, racingDB, t)
+	InsertNewMeeting(&racing.Meeting{
+		Id:       3,
+		Venue:    "Addington",
+		Country:  "NZL",
+		RaceType: racing.RaceType_HARNESS,
+		Date:     "2000-04-06",
+	}, racingDB, t)
+}
+
+func TestListMeetings_Filter(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataMeeting])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	insertTestMeetings(racingDB, t)
+
+	// Set up a new context and request for greyhound and harness meetings on the 5th
+	ctx := context.Background()
+	req := &racing.ListMeetingsRequest{
+		Filter: &racing.ListMeetingsRequestFilter{
+			RaceTypes: []racing.RaceType{racing.RaceType_GREYHOUND, racing.RaceType_HARNESS},
+			Date:      "2000-04-05",
+		},
+	}
+
+	// Call the ListMeetings RPC
+	resp, err := racingService.ListMeetings(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to list meetings: %v", err)
+	}
+
+	expectedMeetings := []*racing.Meeting{
+		&racing.Meeting{
+			Id:       2,
+			Venue:    "The Meadows",
+			Country:  "AUS",
+			RaceType: racing.RaceType_GREYHOUND,
+			Date:     "2000-04-05",
+		},
+	}
+	if !reflect.DeepEqual(resp.Meetings, expectedMeetings) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Meetings, expectedMeetings)
+	}
+}
+
+func TestGetMeeting_Default(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataMeeting])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	insertTestMeetings(racingDB, t)
+
+	// Call the GetMeeting RPC
+	ctx := context.Background()
+	resp, err := racingService.GetMeeting(ctx, &racing.GetMeetingRequest{Id: 3})
+	if err != nil {
+		t.Fatalf("Failed to get meeting: %v", err)
+	}
+
+	expectedMeeting := &racing.Meeting{
+		Id:       3,
+		Venue:    "Addington",
+		Country:  "NZL",
+		RaceType: racing.RaceType_HARNESS,
+		Date:     "2000-04-06",
+	}
+	if !proto.Equal(resp, expectedMeeting) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp, expectedMeeting)
+	}
+
+	// Unknown meetings are reported as not found
+	_, err = racingService.GetMeeting(ctx, &racing.GetMeetingRequest{Id: 99})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
+
+func TestListRace_IncludeMeetings(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataMeeting])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	insertTestMeetings(racingDB, t)
+
+	timeTest, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	// Insert a race record into the races table
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           2,
+		Name:                "Test Race 2",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  3,
+		MeetingId:           1,
+		Name:                "Test Race 3",
+		Number:              2,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest),
+	}, racingDB, t)
+
+	// Call the ListRaces RPC with meetings embedded
+	ctx := context.Background()
+	resp, err := racingService.ListRaces(ctx, &racing.ListRacesRequest{IncludeMeetings: true})
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+
+	if len(resp.Races) != 3 {
+		t.Fatalf("Expected 3 races, got %v", resp.Races)
+	}
+	for _, race := range resp.Races {
+		if race.Meeting == nil || race.Meeting.Id != race.MeetingId {
+			t.Errorf("Expected meeting %d to be embedded in race %d, got %v", race.MeetingId, race.Id, race.Meeting)
+		}
+	}
+	if resp.Races[0].Meeting.Venue != "Flemington" || resp.Races[1].Meeting.Venue != "The Meadows" {
+		t.Errorf("Embedded meetings did not match, got %v", resp.Races)
+	}
+
+	// Meetings are left out unless asked for
+	resp, err = racingService.ListRaces(ctx, &racing.ListRacesRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+	for _, race := range resp.Races {
+		if race.Meeting != nil {
+			t.Errorf("Expected no embedded meeting in race %d, got %v", race.Id, race.Meeting)
+		}
+	}
+}
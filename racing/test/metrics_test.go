@@ -0,0 +1,63 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"git.neds.sh/matty/entain/racing/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// counterValue reads a counter from the default registry by its labels, 0 if it was never set.
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestUnaryMetricsInterceptor_CountsByCode(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/racing.Racing/GetRace"}
+	notFound := map[string]string{"method": "GetRace", "code": codes.NotFound.String()}
+	ok := map[string]string{"method": "GetRace", "code": codes.OK.String()}
+
+	beforeNotFound := counterValue(t, "racing_grpc_requests_total", notFound)
+	beforeOK := counterValue(t, "racing_grpc_requests_total", ok)
+
+	if _, err := service.UnaryMetricsInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "race not found")
+	}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected the handler's error but got %v", err)
+	}
+
+	if got := counterValue(t, "racing_grpc_requests_total", notFound) - beforeNotFound; got != 1 {
+		t.Errorf("Expected 1 NotFound call counted but got %v", got)
+	}
+	if got := counterValue(t, "racing_grpc_requests_total", ok) - beforeOK; got != 0 {
+		t.Errorf("Expected no OK call counted but got %v", got)
+	}
+}
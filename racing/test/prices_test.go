@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestUpdatePrice_HistoryAndLocking(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRunner])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataPrice])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Race 1 is still OPEN, race 2 has been CLOSED by the clock
+	timeTest1, err := time.Parse(time.RFC3339, "5555-04-05T00:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           1,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 1, RaceId: 1, Number: 1, Name: "Test Runner 1"}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 2, RaceId: 1, Number: 2, Name: "Test Runner 2"}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 3, RaceId: 1, Number: 3, Name: "Test Runner 3", Scratched: true}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 4, RaceId: 2, Number: 1, Name: "Test Runner 4"}, racingDB, t)
+
+	ctx := context.Background()
+
+	// Price runner 1 twice and runner 2 once
+	for _, req := range []*racing.UpdatePriceRequest{
+		{RunnerId: 1, Win: 3.5, Place: 1.6},
+		{RunnerId: 2, Win: 2.2, Place: 1.3},
+		{RunnerId: 1, Win: 4, Place: 1.75},
+	} {
+		if _, err := racingService.UpdatePrice(ctx, req); err != nil {
+			t.Fatalf("Failed to update price: %v", err)
+		}
+	}
+
+	// The latest price of each runner is its current price
+	prices, err := racingService.GetPrices(ctx, &racing.GetPricesRequest{RaceId: 1})
+	if err != nil {
+		t.Fatalf("Failed to get prices: %v", err)
+	}
+	if prices.Locked {
+		t.Errorf("Expected prices of an OPEN race to be unlocked")
+	}
+	if len(prices.Prices) != 2 ||
+		prices.Prices[0].RunnerId != 1 || prices.Prices[0].Win != 4 || prices.Prices[0].Place != 1.75 ||
+		prices.Prices[1].RunnerId != 2 || prices.Prices[1].Win != 2.2 {
+		t.Errorf("Unexpected current prices, got %v", prices.Prices)
+	}
+
+	// Every price is kept as history, oldest first
+	history, err := racingService.ListPriceHistory(ctx, &racing.ListPriceHistoryRequest{RunnerId: 1})
+	if err != nil {
+		t.Fatalf("Failed to list price history: %v", err)
+	}
+	if len(history.Prices) != 2 || history.Prices[0].Win != 3.5 || history.Prices[1].Win != 4 {
+		t.Errorf("Unexpected price history, got %v", history.Prices)
+	}
+	if history.Prices[0].Timestamp.AsTime().After(history.Prices[1].Timestamp.AsTime()) {
+		t.Errorf("Expected price history to be oldest first, got %v", history.Prices)
+	}
+
+	// Invalid prices and scratched runners are rejected
+	_, err = racingService.UpdatePrice(ctx, &racing.UpdatePriceRequest{RunnerId: 1, Win: 1, Place: 1.1})
+	if grpc.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected error code %v but got %v", codes.InvalidArgument, grpc.Code(err))
+	}
+	_, err = racingService.UpdatePrice(ctx, &racing.UpdatePriceRequest{RunnerId: 3, Win: 5, Place: 2})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected error code %v but got %v", codes.FailedPrecondition, grpc.Code(err))
+	}
+
+	// Prices of a race closed by the clock are locked
+	_, err = racingService.UpdatePrice(ctx, &racing.UpdatePriceRequest{RunnerId: 4, Win: 5, Place: 2})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected error code %v but got %v", codes.FailedPrecondition, grpc.Code(err))
+	}
+
+	// Prices lock as soon as the race leaves OPEN
+	if _, err := racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 1, Status: racing.Status_SUSPENDED}); err != nil {
+		t.Fatalf("Failed to update race status: %v", err)
+	}
+
+	_, err = racingService.UpdatePrice(ctx, &racing.UpdatePriceRequest{RunnerId: 1, Win: 5, Place: 2})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected error code %v but got %v", codes.FailedPrecondition, grpc.Code(err))
+	}
+
+	prices, err = racingService.GetPrices(ctx, &racing.GetPricesRequest{RaceId: 1})
+	if err != nil {
+		t.Fatalf("Failed to get prices: %v", err)
+	}
+	if !prices.Locked || prices.Prices[0].Win != 4 {
+		t.Errorf("Expected locked prices unchanged, got %v", prices)
+	}
+
+	// Unknown races and runners are reported as not found
+	_, err = racingService.GetPrices(ctx, &racing.GetPricesRequest{RaceId: 99})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+	_, err = racingService.ListPriceHistory(ctx, &racing.ListPriceHistoryRequest{RunnerId: 99})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
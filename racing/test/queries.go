@@ -1,8 +1,14 @@
 package test
 
 const (
-	clearAllDataRace = "clearRace"
-	insertNewRace    = "insertNewRace"
+	clearAllDataRace    = "clearRace"
+	insertNewRace       = "insertNewRace"
+	clearAllDataMeeting = "clearMeeting"
+	insertNewMeeting    = "insertNewMeeting"
+	clearAllDataRunner  = "clearRunner"
+	insertNewRunner     = "insertNewRunner"
+	clearAllDataPrice   = "clearPrice"
+	clearAllDataResult  = "clearResult"
 )
 
 func getRaceQueriesForTest() map[string]string {
@@ -19,5 +25,33 @@ func getRaceQueriesForTest() map[string]string {
 		advertised_start_time)
 		VALUES
 		(?,?,?,?,?,?)`,
+		clearAllDataMeeting: `DELETE FROM meetings`,
+		insertNewMeeting: `
+		INSERT OR IGNORE INTO
+		meetings
+		(id,
+		venue,
+		country,
+		race_type,
+		date)
+		VALUES
+		(?,?,?,?,?)`,
+		clearAllDataRunner: `DELETE FROM runners`,
+		insertNewRunner: `
+		INSERT OR IGNORE INTO
+		runners
+		(id,
+		race_id,
+		number,
+		name,
+		barrier,
+		jockey,
+		trainer,
+		weight,
+		scratched)
+		VALUES
+		(?,?,?,?,?,?,?,?,?)`,
+		clearAllDataPrice:  `DELETE FROM prices`,
+		clearAllDataResult: `DELETE FROM results`,
 	}
 }
@@ -31,8 +31,36 @@ func NewTestDB() (*sql.DB, error) {
 		return nil, err
 	}
 
+	// Recreate the tables so they always match the current schema and start empty.
+	for _, table := range []string{"races", "meetings", "runners", "prices", "results"} {
+		_, err = racingDB.Exec(`DROP TABLE IF EXISTS ` + table)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize the test database by running SQL scripts.
-	_, err = racingDB.Exec(`CREATE TABLE IF NOT EXISTS races (id INTEGER PRIMARY KEY, meeting_id INTEGER, name TEXT, number INTEGER, visible INTEGER, advertised_start_time DATETIME)`)
+	_, err = racingDB.Exec(`CREATE TABLE IF NOT EXISTS races (id INTEGER PRIMARY KEY, meeting_id INTEGER, name TEXT, number INTEGER, visible INTEGER, advertised_start_time DATETIME, status INTEGER, version INTEGER NOT NULL DEFAULT 1)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = racingDB.Exec(`CREATE TABLE IF NOT EXISTS meetings (id INTEGER PRIMARY KEY, venue TEXT, country TEXT, race_type INTEGER, date TEXT)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = racingDB.Exec(`CREATE TABLE IF NOT EXISTS runners (id INTEGER PRIMARY KEY, race_id INTEGER, number INTEGER, name TEXT, barrier INTEGER, jockey TEXT, trainer TEXT, weight REAL, scratched INTEGER)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = racingDB.Exec(`CREATE TABLE IF NOT EXISTS prices (id INTEGER PRIMARY KEY, runner_id INTEGER, win REAL, place REAL, created_at DATETIME)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = racingDB.Exec(`CREATE TABLE IF NOT EXISTS results (id INTEGER PRIMARY KEY, race_id INTEGER, runner_id INTEGER, position INTEGER, win_dividend REAL, place_dividend REAL)`)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +97,11 @@ func TestListRace_Default(t *testing.T) {
 
 	// Set up a new RacingService with the test database
 	racesRepo := db.NewRacesRepo(racingDB)
-	racingService := service.NewRacingService(racesRepo)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
 
 	timeTest, err := time.Parse(time.RFC3339, "1992-04-05T00:00:00Z")
 	// Insert a race record into the races table
@@ -118,6 +150,7 @@ func TestListRace_Default(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 		&racing.Race{
 			Id:                  2,
@@ -127,6 +160,7 @@ func TestListRace_Default(t *testing.T) {
 			Visible:             false,
 			AdvertisedStartTime: timestamppb.New(timeTest),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 		&racing.Race{
 			Id:                  3,
@@ -136,6 +170,7 @@ func TestListRace_Default(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 	}
 	if !reflect.DeepEqual(resp.Races, expectedRaces) {
@@ -153,7 +188,11 @@ func TestListRace_VisibleFilter(t *testing.T) {
 
 	// Set up a new RacingService with the test database
 	racesRepo := db.NewRacesRepo(racingDB)
-	racingService := service.NewRacingService(racesRepo)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
 
 	timeTest, err := time.Parse(time.RFC3339, "1992-04-05T00:00:00Z")
 	// Insert a race record into the races table
@@ -206,6 +245,7 @@ func TestListRace_VisibleFilter(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 		&racing.Race{
 			Id:                  3,
@@ -215,6 +255,7 @@ func TestListRace_VisibleFilter(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 	}
 	if !reflect.DeepEqual(resp.Races, expectedRaces) {
@@ -232,7 +273,11 @@ func TestListRace_OrderByAdvertiseStartTime(t *testing.T) {
 
 	// Set up a new RacingService with the test database
 	racesRepo := db.NewRacesRepo(racingDB)
-	racingService := service.NewRacingService(racesRepo)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
 
 	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
 	timeTest2, err := time.Parse(time.RFC3339, "2001-04-05T00:00:00Z")
@@ -286,6 +331,7 @@ func TestListRace_OrderByAdvertiseStartTime(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest3),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 		&racing.Race{
 			Id:                  2,
@@ -295,6 +341,7 @@ func TestListRace_OrderByAdvertiseStartTime(t *testing.T) {
 			Visible:             false,
 			AdvertisedStartTime: timestamppb.New(timeTest2),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 		&racing.Race{
 			Id:                  1,
@@ -304,6 +351,7 @@ func TestListRace_OrderByAdvertiseStartTime(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest1),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 	}
 	if !reflect.DeepEqual(resp.Races, expectedRaces) {
@@ -321,7 +369,11 @@ func TestListRace_WithStatusOpenOrClose(t *testing.T) {
 
 	// Set up a new RacingService with the test database
 	racesRepo := db.NewRacesRepo(racingDB)
-	racingService := service.NewRacingService(racesRepo)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
 
 	// Time date is diferent data
 	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
@@ -363,6 +415,7 @@ func TestListRace_WithStatusOpenOrClose(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest1),
 			Status:              racing.Status_CLOSED,
+			Version:             1,
 		},
 		&racing.Race{
 			Id:                  3,
@@ -372,6 +425,7 @@ func TestListRace_WithStatusOpenOrClose(t *testing.T) {
 			Visible:             true,
 			AdvertisedStartTime: timestamppb.New(timeTest3),
 			Status:              racing.Status_OPEN,
+			Version:             1,
 		},
 	}
 	if !reflect.DeepEqual(resp.Races, expectedRaces) {
@@ -389,7 +443,11 @@ func TestGetRace_Default(t *testing.T) {
 
 	// Set up a new RacingService with the test database
 	racesRepo := db.NewRacesRepo(racingDB)
-	racingService := service.NewRacingService(racesRepo)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
 
 	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
 	timeTest2, err := time.Parse(time.RFC3339, "2001-04-05T00:00:00Z")
@@ -439,6 +497,7 @@ func TestGetRace_Default(t *testing.T) {
 		Visible:             true,
 		AdvertisedStartTime: timestamppb.New(timeTest1),
 		Status:              racing.Status_CLOSED,
+		Version:             1,
 	}
 	if !proto.Equal(resp, expectedRace) {
 		t.Errorf("Response did not match expected value. Got %v, expected %v", resp, expectedRace)
@@ -455,7 +514,11 @@ func TestGetRace_IdIsNotFound(t *testing.T) {
 
 	// Set up a new RacingService with the test database
 	racesRepo := db.NewRacesRepo(racingDB)
-	racingService := service.NewRacingService(racesRepo)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
 
 	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
 	timeTest2, err := time.Parse(time.RFC3339, "2001-04-05T00:00:00Z")
@@ -497,3 +560,405 @@ func TestGetRace_IdIsNotFound(t *testing.T) {
 		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
 	}
 }
+
+func TestListRace_Pagination(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Race 1 and 3 share a start time so the id tie-breaker is exercised
+	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "2001-04-05T00:00:00Z")
+	// Insert a race record into the races table
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           2,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             false,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  3,
+		MeetingId:           3,
+		Name:                "Test Race 3",
+		Number:              3,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, racingDB, t)
+
+	// Set up a new context and request for the first page
+	ctx := context.Background()
+	orderBy := racing.OrderBy_ASC
+	req := &racing.ListRacesRequest{
+		Filter: &racing.ListRacesRequestFilter{
+			OrderBy: &orderBy,
+		},
+		PageSize: 2,
+	}
+
+	// Call the ListRaces RPC for the first page
+	resp, err := racingService.ListRaces(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+
+	if len(resp.Races) != 2 || resp.Races[0].Id != 1 || resp.Races[1].Id != 3 {
+		t.Fatalf("Expected races [1 3] on the first page, got %v", resp.Races)
+	}
+	if resp.NextPageToken == "" {
+		t.Fatalf("Expected a next page token on the first page")
+	}
+
+	// Call the ListRaces RPC for the second page
+	req.PageToken = resp.NextPageToken
+	resp, err = racingService.ListRaces(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+
+	if len(resp.Races) != 1 || resp.Races[0].Id != 2 {
+		t.Fatalf("Expected race [2] on the second page, got %v", resp.Races)
+	}
+	if resp.NextPageToken != "" {
+		t.Errorf("Expected no next page token on the last page, got %q", resp.NextPageToken)
+	}
+}
+
+func TestListRace_InvalidPageToken(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Set up a new context and request with a token that was not issued by ListRaces
+	ctx := context.Background()
+	req := &racing.ListRacesRequest{PageSize: 2, PageToken: "not-a-token"}
+
+	// Call the ListRaces RPC
+	_, err = racingService.ListRaces(ctx, req)
+	// Check that the error is the expected type
+	if grpc.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected error code %v but got %v", codes.InvalidArgument, grpc.Code(err))
+	}
+}
+
+func TestListRace_StartTimeWindowFilter(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "2001-04-05T00:00:00Z")
+	timeTest3, err := time.Parse(time.RFC3339, "2002-04-05T00:00:00Z")
+	// Insert a race record into the races table
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           2,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             false,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  3,
+		MeetingId:           3,
+		Name:                "Test Race 3",
+		Number:              3,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest3),
+	}, racingDB, t)
+
+	// Set up a new context and request for races starting from race 2 up to (not including) race 3
+	ctx := context.Background()
+	visible := false
+	req := &racing.ListRacesRequest{
+		Filter: &racing.ListRacesRequestFilter{
+			StartAfter:  timestamppb.New(timeTest2),
+			StartBefore: timestamppb.New(timeTest3),
+		}}
+
+	// Call the ListRaces RPC
+	resp, err := racingService.ListRaces(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+
+	expectedRaces := []*racing.Race{
+		&racing.Race{
+			Id:                  2,
+			MeetingId:           2,
+			Name:                "Test Race 2",
+			Number:              2,
+			Visible:             false,
+			AdvertisedStartTime: timestamppb.New(timeTest2),
+			Status:              racing.Status_CLOSED,
+			Version:             1,
+		},
+	}
+	if !reflect.DeepEqual(resp.Races, expectedRaces) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Races, expectedRaces)
+	}
+
+	// The window combines with the other filters
+	req.Filter.StartBefore = nil
+	req.Filter.Visible = &visible
+	resp, err = racingService.ListRaces(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+
+	if !reflect.DeepEqual(resp.Races, expectedRaces) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Races, expectedRaces)
+	}
+
+	// An empty window is rejected
+	req.Filter.StartBefore = timestamppb.New(timeTest1)
+	_, err = racingService.ListRaces(ctx, req)
+	if grpc.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected error code %v but got %v", codes.InvalidArgument, grpc.Code(err))
+	}
+}
+
+func TestListRace_StatusFilter(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// One race in the past and two in the future
+	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "5555-04-05T00:00:00Z")
+	// Insert a race record into the races table
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           2,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             false,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  3,
+		MeetingId:           3,
+		Name:                "Test Race 3",
+		Number:              3,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, racingDB, t)
+
+	// Set up a new context and request for open races only, one per page
+	ctx := context.Background()
+	req := &racing.ListRacesRequest{
+		Filter: &racing.ListRacesRequestFilter{
+			Statuses: []racing.Status{racing.Status_OPEN},
+		},
+		PageSize: 1,
+	}
+
+	// Page through the ListRaces RPC
+	var gotIds []int64
+	for {
+		resp, err := racingService.ListRaces(ctx, req)
+		if err != nil {
+			t.Fatalf("Failed to list races: %v", err)
+		}
+
+		for _, race := range resp.Races {
+			if race.Status != racing.Status_OPEN {
+				t.Errorf("Expected only OPEN races, got %v", race)
+			}
+			gotIds = append(gotIds, race.Id)
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+
+	if !reflect.DeepEqual(gotIds, []int64{2, 3}) {
+		t.Errorf("Expected open races [2 3], got %v", gotIds)
+	}
+
+	// Set up a request for closed races only
+	req = &racing.ListRacesRequest{
+		Filter: &racing.ListRacesRequestFilter{
+			Statuses: []racing.Status{racing.Status_CLOSED},
+		},
+	}
+
+	resp, err := racingService.ListRaces(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+
+	if len(resp.Races) != 1 || resp.Races[0].Id != 1 || resp.Races[0].Status != racing.Status_CLOSED {
+		t.Errorf("Expected closed race [1], got %v", resp.Races)
+	}
+}
+
+func TestUpdateRaceStatus_Transitions(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Race 1 is in the future (OPEN) and race 2 in the past (CLOSED by the clock)
+	timeTest1, err := time.Parse(time.RFC3339, "5555-04-05T00:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	// Insert a race record into the races table
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           2,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, racingDB, t)
+
+	ctx := context.Background()
+
+	// OPEN -> SUSPENDED is allowed and is stored
+	resp, err := racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 1, Status: racing.Status_SUSPENDED})
+	if err != nil {
+		t.Fatalf("Failed to update race status: %v", err)
+	}
+	if resp.Status != racing.Status_SUSPENDED {
+		t.Errorf("Expected status %v, got %v", racing.Status_SUSPENDED, resp.Status)
+	}
+
+	// The stored status is returned by GetRace and can be filtered on
+	race, err := racingService.GetRace(ctx, &racing.GetRaceRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("Failed to get race: %v", err)
+	}
+	if race.Status != racing.Status_SUSPENDED {
+		t.Errorf("Expected status %v, got %v", racing.Status_SUSPENDED, race.Status)
+	}
+
+	list, err := racingService.ListRaces(ctx, &racing.ListRacesRequest{
+		Filter: &racing.ListRacesRequestFilter{Statuses: []racing.Status{racing.Status_SUSPENDED}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to list races: %v", err)
+	}
+	if len(list.Races) != 1 || list.Races[0].Id != 1 {
+		t.Errorf("Expected suspended race [1], got %v", list.Races)
+	}
+
+	// SUSPENDED -> FINAL is illegal
+	_, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 1, Status: racing.Status_FINAL})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected error code %v but got %v", codes.FailedPrecondition, grpc.Code(err))
+	}
+
+	// A race closed by the clock follows the CLOSED transitions
+	_, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 2, Status: racing.Status_OPEN})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected error code %v but got %v", codes.FailedPrecondition, grpc.Code(err))
+	}
+
+	// INTERIM and FINAL are only reached by resulting the race
+	for _, to := range []racing.Status{racing.Status_INTERIM, racing.Status_FINAL} {
+		_, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 2, Status: to})
+		if grpc.Code(err) != codes.FailedPrecondition {
+			t.Errorf("Expected error code %v moving to %v but got %v", codes.FailedPrecondition, to, grpc.Code(err))
+		}
+	}
+
+	resp, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 2, Status: racing.Status_ABANDONED})
+	if err != nil {
+		t.Fatalf("Failed to update race status: %v", err)
+	}
+	if resp.Status != racing.Status_ABANDONED {
+		t.Errorf("Expected status %v, got %v", racing.Status_ABANDONED, resp.Status)
+	}
+
+	// Unknown races are reported as not found
+	_, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 99, Status: racing.Status_CLOSED})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
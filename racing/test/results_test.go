@@ -0,0 +1,274 @@
+package test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSetResult_DeadHeatAndStatus(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRunner])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataResult])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Race 1 has been run (CLOSED by the clock), race 2 is still OPEN
+	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "5555-04-05T00:00:00Z")
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           1,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 1, RaceId: 1, Number: 1, Name: "Test Runner 1"}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 2, RaceId: 1, Number: 2, Name: "Test Runner 2"}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 3, RaceId: 1, Number: 3, Name: "Test Runner 3"}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 4, RaceId: 1, Number: 4, Name: "Test Runner 4", Scratched: true}, racingDB, t)
+	InsertNewRunner(&racing.Runner{Id: 5, RaceId: 2, Number: 1, Name: "Test Runner 5"}, racingDB, t)
+
+	ctx := context.Background()
+
+	// Runners 1 and 2 dead heat for first, so runner 3 runs third
+	deadHeat := []*racing.Result{
+		{RunnerId: 2, Position: 1, WinDividend: 2.4, PlaceDividend: 1.3},
+		{RunnerId: 1, Position: 1, WinDividend: 3.1, PlaceDividend: 1.5},
+		{RunnerId: 3, Position: 3, PlaceDividend: 2.2},
+	}
+
+	// Invalid placings are rejected
+	for _, results := range [][]*racing.Result{
+		nil,
+		{{RunnerId: 1, Position: 1}, {RunnerId: 2, Position: 1}, {RunnerId: 3, Position: 2}},
+		{{RunnerId: 1, Position: 1}, {RunnerId: 4, Position: 2}},
+		{{RunnerId: 1, Position: 1}, {RunnerId: 5, Position: 2}},
+		{{RunnerId: 1, Position: 1}, {RunnerId: 1, Position: 2}},
+	} {
+		_, err = racingService.SetResult(ctx, &racing.SetResultRequest{RaceId: 1, Results: results})
+		if grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected error code %v for %v but got %v", codes.InvalidArgument, results, grpc.Code(err))
+		}
+	}
+
+	// Interim results move the race to INTERIM and are returned by GetRace
+	race, err := racingService.SetResult(ctx, &racing.SetResultRequest{RaceId: 1, Results: deadHeat})
+	if err != nil {
+		t.Fatalf("Failed to set result: %v", err)
+	}
+
+	expectedResults := []*racing.Result{
+		&racing.Result{RaceId: 1, RunnerId: 1, Position: 1, WinDividend: 3.1, PlaceDividend: 1.5},
+		&racing.Result{RaceId: 1, RunnerId: 2, Position: 1, WinDividend: 2.4, PlaceDividend: 1.3},
+		&racing.Result{RaceId: 1, RunnerId: 3, Position: 3, PlaceDividend: 2.2},
+	}
+	if race.Status != racing.Status_INTERIM {
+		t.Errorf("Expected status %v, got %v", racing.Status_INTERIM, race.Status)
+	}
+	if !reflect.DeepEqual(race.Results, expectedResults) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", race.Results, expectedResults)
+	}
+
+	// Interim results can be amended, then made FINAL
+	race, err = racingService.SetResult(ctx, &racing.SetResultRequest{RaceId: 1, Results: deadHeat, Final: true})
+	if err != nil {
+		t.Fatalf("Failed to set result: %v", err)
+	}
+	if race.Status != racing.Status_FINAL || len(race.Results) != 3 {
+		t.Errorf("Expected FINAL race with 3 results, got %v", race)
+	}
+
+	// FINAL results cannot be changed, and OPEN races cannot be resulted
+	_, err = racingService.SetResult(ctx, &racing.SetResultRequest{RaceId: 1, Results: deadHeat, Final: true})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected error code %v but got %v", codes.FailedPrecondition, grpc.Code(err))
+	}
+	_, err = racingService.SetResult(ctx, &racing.SetResultRequest{RaceId: 2, Results: []*racing.Result{{RunnerId: 5, Position: 1}}})
+	if grpc.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected error code %v but got %v", codes.FailedPrecondition, grpc.Code(err))
+	}
+}
+
+func TestListResults_Filter(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRunner])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataResult])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataMeeting])
+	insertTestMeetings(racingDB, t)
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Three run races over three meetings. The NZL meeting is held on 2000-04-06 though its race
+	// starts on 2000-04-05 in UTC.
+	timeTest1, err := time.Parse(time.RFC3339, "2000-04-05T01:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "2000-04-05T22:00:00Z")
+	if err != nil {
+		t.Fatalf("Failed to parse time: %v", err)
+	}
+	for i, race := range []*racing.Race{
+		{Id: 1, MeetingId: 1, Name: "Test Race 1", Number: 1, AdvertisedStartTime: timestamppb.New(timeTest1)},
+		{Id: 2, MeetingId: 2, Name: "Test Race 2", Number: 1, AdvertisedStartTime: timestamppb.New(timeTest1)},
+		{Id: 3, MeetingId: 3, Name: "Test Race 3", Number: 1, AdvertisedStartTime: timestamppb.New(timeTest2)},
+	} {
+		InsertNewRace(race, racingDB, t)
+		InsertNewRunner(&racing.Runner{Id: race.Id, RaceId: race.Id, Number: 1, Name: "Test Runner"}, racingDB, t)
+
+		_, err := racingService.SetResult(context.Background(), &racing.SetResultRequest{
+			RaceId:  race.Id,
+			Results: []*racing.Result{{RunnerId: race.Id, Position: 1, WinDividend: 2}},
+			Final:   i != 2,
+		})
+		if err != nil {
+			t.Fatalf("Failed to set result: %v", err)
+		}
+	}
+
+	// Collects the races of the results returned for a filter
+	racesOf := func(filter *racing.ListResultsRequestFilter) []int64 {
+		resp, err := racingService.ListResults(context.Background(), &racing.ListResultsRequest{Filter: filter})
+		if err != nil {
+			t.Fatalf("Failed to list results: %v", err)
+		}
+
+		var ids []int64
+		for _, result := range resp.Results {
+			ids = append(ids, result.RaceId)
+		}
+
+		return ids
+	}
+
+	if got := racesOf(nil); !reflect.DeepEqual(got, []int64{1, 2, 3}) {
+		t.Errorf("Expected results of races [1 2 3], got %v", got)
+	}
+	if got := racesOf(&racing.ListResultsRequestFilter{MeetingIds: []int64{1, 3}}); !reflect.DeepEqual(got, []int64{1, 3}) {
+		t.Errorf("Expected results of races [1 3], got %v", got)
+	}
+	if got := racesOf(&racing.ListResultsRequestFilter{MeetingIds: []int64{1}, Date: "2000-04-05"}); !reflect.DeepEqual(got, []int64{1}) {
+		t.Errorf("Expected results of races [1], got %v", got)
+	}
+	if got := racesOf(&racing.ListResultsRequestFilter{Date: "2000-04-05"}); !reflect.DeepEqual(got, []int64{1, 2}) {
+		t.Errorf("Expected results of races [1 2], got %v", got)
+	}
+	if got := racesOf(&racing.ListResultsRequestFilter{Date: "2000-04-06"}); !reflect.DeepEqual(got, []int64{3}) {
+		t.Errorf("Expected results of races [3], got %v", got)
+	}
+	if got := racesOf(&racing.ListResultsRequestFilter{MeetingIds: []int64{1, 3}, FinalOnly: true}); !reflect.DeepEqual(got, []int64{1}) {
+		t.Errorf("Expected results of races [1], got %v", got)
+	}
+
+	// Dates in any other format are rejected rather than matching nothing
+	for _, date := range []string{"2000-4-5", "05/04/2000", "2000-04-05T00:00:00Z", "2000-02-30"} {
+		_, err := racingService.ListResults(context.Background(), &racing.ListResultsRequest{
+			Filter: &racing.ListResultsRequestFilter{Date: date},
+		})
+		if grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected error code %v for date %q but got %v", codes.InvalidArgument, date, grpc.Code(err))
+		}
+	}
+}
+
+func TestUpdateRaceStatus_AbandonDiscardsResults(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRunner])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataResult])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Both races have been run and have interim results
+	timeTest, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatalf("Failed to parse time: %v", err)
+	}
+	for _, race := range []*racing.Race{
+		{Id: 1, MeetingId: 1, Name: "Test Race 1", Number: 1, AdvertisedStartTime: timestamppb.New(timeTest)},
+		{Id: 2, MeetingId: 1, Name: "Test Race 2", Number: 2, AdvertisedStartTime: timestamppb.New(timeTest)},
+	} {
+		InsertNewRace(race, racingDB, t)
+		InsertNewRunner(&racing.Runner{Id: race.Id, RaceId: race.Id, Number: 1, Name: "Test Runner"}, racingDB, t)
+
+		_, err := racingService.SetResult(context.Background(), &racing.SetResultRequest{
+			RaceId:  race.Id,
+			Results: []*racing.Result{{RunnerId: race.Id, Position: 1, WinDividend: 2}},
+		})
+		if err != nil {
+			t.Fatalf("Failed to set result: %v", err)
+		}
+	}
+
+	// Race 1 is abandoned after its interim results were declared
+	race, err := racingService.UpdateRaceStatus(context.Background(), &racing.UpdateRaceStatusRequest{Id: 1, Status: racing.Status_ABANDONED})
+	if err != nil {
+		t.Fatalf("Failed to update race status: %v", err)
+	}
+	if race.Status != racing.Status_ABANDONED {
+		t.Errorf("Expected status %v, got %v", racing.Status_ABANDONED, race.Status)
+	}
+
+	race, err = racingService.GetRace(context.Background(), &racing.GetRaceRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("Failed to get race: %v", err)
+	}
+	if len(race.Results) != 0 {
+		t.Errorf("Expected no results for an abandoned race, got %v", race.Results)
+	}
+
+	resp, err := racingService.ListResults(context.Background(), &racing.ListResultsRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list results: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].RaceId != 2 {
+		t.Errorf("Expected only the results of race 2, got %v", resp.Results)
+	}
+}
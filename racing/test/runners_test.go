@@ -0,0 +1,198 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func InsertNewRunner(runner *racing.Runner, r *sql.DB, t *testing.T) {
+	_, err := r.Exec(getRaceQueriesForTest()[insertNewRunner],
+		&runner.Id,
+		&runner.RaceId,
+		&runner.Number,
+		&runner.Name,
+		&runner.Barrier,
+		&runner.Jockey,
+		&runner.Trainer,
+		&runner.Weight,
+		&runner.Scratched,
+	)
+
+	if err != nil {
+		t.Fatalf("Failed to insert runner record: %v", err)
+	}
+}
+
+// insertTestRaceCard seeds race 1 with two runners, inserted out of number order,
+// and race 2 with one runner.
+func insertTestRaceCard(racingDB *sql.DB, t *testing.T) {
+	timeTest, err := time.Parse(time.RFC3339, "2000-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatalf("Failed to parse time: %v", err)
+	}
+
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           1,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(timeTest),
+	}, racingDB, t)
+
+	InsertNewRunner(&racing.Runner{
+		Id:        2,
+		RaceId:    1,
+		Number:    2,
+		Name:      "Test Runner 2",
+		Barrier:   1,
+		Jockey:    "Test Jockey 2",
+		Trainer:   "Test Trainer 2",
+		Weight:    56.5,
+		Scratched: true,
+	}, racingDB, t)
+	InsertNewRunner(&racing.Runner{
+		Id:      1,
+		RaceId:  1,
+		Number:  1,
+		Name:    "Test Runner 1",
+		Barrier: 4,
+		Jockey:  "Test Jockey 1",
+		Trainer: "Test Trainer 1",
+		Weight:  58,
+	}, racingDB, t)
+	InsertNewRunner(&racing.Runner{
+		Id:      3,
+		RaceId:  2,
+		Number:  1,
+		Name:    "Test Runner 3",
+		Barrier: 2,
+		Jockey:  "Test Jockey 3",
+		Trainer: "Test Trainer 3",
+		Weight:  55,
+	}, racingDB, t)
+}
+
+// expectedTestRaceCard is the race card of race 1 seeded by insertTestRaceCard.
+func expectedTestRaceCard() []*racing.Runner {
+	return []*racing.Runner{
+		&racing.Runner{
+			Id:      1,
+			RaceId:  1,
+			Number:  1,
+			Name:    "Test Runner 1",
+			Barrier: 4,
+			Jockey:  "Test Jockey 1",
+			Trainer: "Test Trainer 1",
+			Weight:  58,
+		},
+		&racing.Runner{
+			Id:        2,
+			RaceId:    1,
+			Number:    2,
+			Name:      "Test Runner 2",
+			Barrier:   1,
+			Jockey:    "Test Jockey 2",
+			Trainer:   "Test Trainer 2",
+			Weight:    56.5,
+			Scratched: true,
+		},
+	}
+}
+
+func TestListRunners_Default(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRunner])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	insertTestRaceCard(racingDB, t)
+
+	// Call the ListRunners RPC
+	ctx := context.Background()
+	resp, err := racingService.ListRunners(ctx, &racing.ListRunnersRequest{RaceId: 1})
+	if err != nil {
+		t.Fatalf("Failed to list runners: %v", err)
+	}
+
+	expectedRunners := expectedTestRaceCard()
+	if !reflect.DeepEqual(resp.Runners, expectedRunners) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Runners, expectedRunners)
+	}
+
+	// Unknown races are reported as not found
+	_, err = racingService.ListRunners(ctx, &racing.ListRunnersRequest{RaceId: 99})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
+
+func TestGetRace_IncludeRunners(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRunner])
+
+	// Set up a new RacingService with the test database
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	insertTestRaceCard(racingDB, t)
+
+	// Call the GetRace RPC with runners inline
+	ctx := context.Background()
+	resp, err := racingService.GetRace(ctx, &racing.GetRaceRequest{Id: 1, IncludeRunners: true})
+	if err != nil {
+		t.Fatalf("Failed to get race: %v", err)
+	}
+
+	expectedRunners := expectedTestRaceCard()
+	if !reflect.DeepEqual(resp.Runners, expectedRunners) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Runners, expectedRunners)
+	}
+
+	// Runners are left out unless asked for
+	resp, err = racingService.GetRace(ctx, &racing.GetRaceRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("Failed to get race: %v", err)
+	}
+	if len(resp.Runners) != 0 {
+		t.Errorf("Expected no runners, got %v", resp.Runners)
+	}
+}
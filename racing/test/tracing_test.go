@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTracing_RepoSpansJoinCallerTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	racingDB, err := NewTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer racingDB.Close()
+
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour)),
+	}, racingDB, t)
+
+	// Serve the racing service over an in-memory connection, traced on both ends like the
+	// gateway and the service are
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()))
+	racing.RegisterRacingServer(grpcServer, racingService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "request")
+	if _, err := racing.NewRacingClient(conn).GetRace(ctx, &racing.GetRaceRequest{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	// The client and server spans of the call share its name
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.SpanKind() != trace.SpanKindClient || span.Name() != "racing.Racing/GetRace" {
+			spans[span.Name()] = span
+		}
+	}
+
+	server, ok := spans["racing.Racing/GetRace"]
+	if !ok {
+		t.Fatalf("Expected a span for the GetRace call but got %v", spans)
+	}
+
+	// The server span continues the caller's trace, and the queries are children of it
+	if server.SpanContext().TraceID() != root.SpanContext().TraceID() {
+		t.Errorf("Expected the server span in the caller's trace %s but got %s", root.SpanContext().TraceID(), server.SpanContext().TraceID())
+	}
+
+	for _, name := range []string{"races.Get", "results.ListByRace"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("Expected a span for %s", name)
+			continue
+		}
+
+		if span.Parent().SpanID() != server.SpanContext().SpanID() {
+			t.Errorf("Expected %s to be a child of the server span but its parent is %s", name, span.Parent().SpanID())
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/racing/db"
+	"git.neds.sh/matty/entain/racing/proto/racing"
+	"git.neds.sh/matty/entain/racing/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestWatchRaces_Events(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Race 1 is far in the future, race 2 jumps shortly after the watch starts and race 3 is
+	// at another meeting, outside the watched filter
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour)),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           1,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(1500 * time.Millisecond)),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  3,
+		MeetingId:           2,
+		Name:                "Test Race 3",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour)),
+	}, racingDB, t)
+
+	// Serve the racing service over an in-memory connection
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	racing.RegisterRacingServer(grpcServer, racingService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := racing.NewRacingClient(conn).WatchRaces(ctx, &racing.WatchRacesRequest{
+		Filter: &racing.ListRacesRequestFilter{MeetingIds: []int64{1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectEvent := func(eventType racing.RaceEventType, raceID int64, raceStatus racing.Status) {
+		t.Helper()
+
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Expected %v event for race %d but got %v", eventType, raceID, err)
+		}
+
+		if event.Type != eventType || event.Race.Id != raceID || event.Race.Status != raceStatus {
+			t.Errorf("Expected %v event for race %d in %v but got %v event for race %d in %v",
+				eventType, raceID, raceStatus, event.Type, event.Race.Id, event.Race.Status)
+		}
+	}
+
+	// The watched races are sent first
+	expectEvent(racing.RaceEventType_CREATED, 1, racing.Status_OPEN)
+	expectEvent(racing.RaceEventType_CREATED, 2, racing.Status_OPEN)
+
+	// Changes made through the service are sent straight away
+	_, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 1, Status: racing.Status_SUSPENDED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(racing.RaceEventType_UPDATED, 1, racing.Status_SUSPENDED)
+
+	// Race 2 closes once it crosses its advertised start time
+	expectEvent(racing.RaceEventType_UPDATED, 2, racing.Status_CLOSED)
+
+	// Changes made elsewhere are picked up as well
+	_, err = racingDB.Exec("DELETE FROM races WHERE id = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(racing.RaceEventType_DELETED, 1, racing.Status_SUSPENDED)
+}
+
+func TestWatchChanges_Signals(t *testing.T) {
+	// Set up a test database with for testing
+	racingDB, err := NewTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer racingDB.Close()
+
+	// clear the data
+	racingDB.Exec(getRaceQueriesForTest()[clearAllDataRace])
+
+	racesRepo := db.NewRacesRepo(racingDB)
+	meetingsRepo := db.NewMeetingsRepo(racingDB)
+	runnersRepo := db.NewRunnersRepo(racingDB)
+	pricesRepo := db.NewPricesRepo(racingDB)
+	resultsRepo := db.NewResultsRepo(racingDB)
+	racingService := service.NewRacingService(racesRepo, meetingsRepo, runnersRepo, pricesRepo, resultsRepo)
+
+	// Race 1 is far in the future and race 2 jumps shortly after the watch starts
+	InsertNewRace(&racing.Race{
+		Id:                  1,
+		MeetingId:           1,
+		Name:                "Test Race 1",
+		Number:              1,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour)),
+	}, racingDB, t)
+	InsertNewRace(&racing.Race{
+		Id:                  2,
+		MeetingId:           1,
+		Name:                "Test Race 2",
+		Number:              2,
+		Visible:             true,
+		AdvertisedStartTime: timestamppb.New(time.Now().Add(1500 * time.Millisecond)),
+	}, racingDB, t)
+
+	// Serve the racing service over an in-memory connection
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	racing.RegisterRacingServer(grpcServer, racingService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := racing.NewRacingClient(conn).WatchChanges(ctx, &racing.WatchChangesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signals := make(chan error, 10)
+	go func() {
+		for {
+			_, err := stream.Recv()
+			signals <- err
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	expectSignal := func(what string) {
+		t.Helper()
+
+		select {
+		case err := <-signals:
+			if err != nil {
+				t.Fatalf("Expected a signal %s but got %v", what, err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Expected a signal %s", what)
+		}
+	}
+
+	expectNoSignal := func() {
+		t.Helper()
+
+		select {
+		case err := <-signals:
+			t.Fatalf("Expected no signal while nothing changes but got one (%v)", err)
+		case <-time.After(300 * time.Millisecond):
+		}
+	}
+
+	expectSignal("once watching")
+	expectNoSignal()
+
+	// Changes made through the service are signalled straight away
+	_, err = racingService.UpdateRaceStatus(ctx, &racing.UpdateRaceStatusRequest{Id: 1, Status: racing.Status_SUSPENDED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectSignal("for the suspended race")
+
+	// Race 2 closing is signalled once it reads as closed
+	expectSignal("for the closing race")
+
+	race, err := racesRepo.Get(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if race.Status != racing.Status_CLOSED {
+		t.Errorf("Expected race 2 to be %v when signalled but got %v", racing.Status_CLOSED, race.Status)
+	}
+
+	// No race is left to close with the clock
+	expectNoSignal()
+}
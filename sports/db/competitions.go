@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"sports/proto/sports"
+)
+
+// CompetitionsRepo provides repository access to competitions.
+type CompetitionsRepo interface {
+	// Init will initialise our competitions repository.
+	Init() error
+
+	// List will return a list of competitions.
+	List(ctx context.Context, filter *sports.ListCompetitionsRequestFilter) ([]*sports.Competition, error)
+}
+
+type competitionsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewCompetitionsRepo creates a new competitions repository
+func NewCompetitionsRepo(db *sql.DB) CompetitionsRepo {
+	return &competitionsRepo{db: db}
+}
+
+// Init prepares the competition repository dummy data.
+func (r *competitionsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with some dummy competitions.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *competitionsRepo) List(ctx context.Context, filter *sports.ListCompetitionsRequestFilter) ([]*sports.Competition, error) {
+	ctx, done := observeQuery(ctx, "competitions", "List")
+	defer done()
+
+	query, args := r.applyFilter(getCompetitionQueries()[competitionsList], filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var competitions []*sports.Competition
+
+	for rows.Next() {
+		var competition sports.Competition
+
+		if err := rows.Scan(&competition.Id, &competition.SportId, &competition.Name); err != nil {
+			return nil, err
+		}
+
+		competitions = append(competitions, &competition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return competitions, nil
+}
+
+func (r *competitionsRepo) applyFilter(query string, filter *sports.ListCompetitionsRequestFilter) (string, []interface{}) {
+	var args []interface{}
+
+	if filter != nil && len(filter.SportIds) > 0 {
+		query += " WHERE sport_id IN (" + strings.Repeat("?,", len(filter.SportIds)-1) + "?)"
+
+		for _, sportID := range filter.SportIds {
+			args = append(args, sportID)
+		}
+	}
+
+	query += " ORDER BY sport_id ASC, name ASC, id ASC"
+
+	return query, args
+}
@@ -1,31 +1,290 @@
 package db
 
 import (
+	"database/sql"
+	"strings"
 	"time"
 
+	"sports/proto/sports"
+
 	"syreclabs.com/go/faker"
 )
 
+// seedSports are the dummy sports seeded, each with the competitions played in it and whether
+// it is contested by individuals rather than teams.
+var seedSports = []struct {
+	name         string
+	competitions []string
+	individual   bool
+}{
+	{"Soccer", []string{"English Premier League", "A-League", "UEFA Champions League"}, false},
+	{"Basketball", []string{"NBA", "NBL"}, false},
+	{"Tennis", []string{"Australian Open", "Wimbledon"}, true},
+	{"Cricket", []string{"Big Bash League", "Indian Premier League"}, false},
+	{"Rugby League", []string{"NRL", "State of Origin"}, false},
+}
+
+// seedEvents is the number of dummy events seeded.
+const seedEvents = 100
+
+// seedMarkets are the dummy markets seeded on every event, each with the names of its selections.
+var seedMarkets = []struct {
+	name       string
+	marketType sports.MarketType
+	selections []string
+}{
+	{"Head to Head", sports.MarketType_HEAD_TO_HEAD, []string{"Home", "Away"}},
+	{"Line", sports.MarketType_LINE, []string{"Home", "Away"}},
+	{"Total Points", sports.MarketType_TOTAL, []string{"Over", "Under"}},
+}
+
 func (r *sportsRepo) seed() error {
-	sportsChoices := []string{"Horse Racing", "Car Racing", "Dog Racing", "Human Racing", "Bike Racing"}
+	// Databases created before sports were modelled kept events in the sports table. It becomes the
+	// events table, keeping its rows, and the events seed adds the columns events have gained since.
+	err := renameTableIfHasColumn(r.db, "sports", "city_address", "events")
 
-	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS sports (id INTEGER PRIMARY KEY, name TEXT, city_address TEXT, num_of_participants INTEGER, advertised_start_time DATETIME)`)
+	var statement *sql.Stmt
+	if err == nil {
+		statement, err = r.db.Prepare(`CREATE TABLE IF NOT EXISTS sports (id INTEGER PRIMARY KEY, name TEXT)`)
+	}
 	if err == nil {
 		_, err = statement.Exec()
 	}
 
-	for i := 1; i <= 100; i++ {
-		statement, err = r.db.Prepare(`INSERT OR IGNORE INTO sports(id, name, city_address, num_of_participants, advertised_start_time) VALUES (?,?,?,?,?)`)
+	for i, sport := range seedSports {
+		statement, err = r.db.Prepare(`INSERT OR IGNORE INTO sports(id, name) VALUES (?,?)`)
+		if err == nil {
+			_, err = statement.Exec(i+1, sport.name)
+		}
+	}
+
+	return err
+}
+
+func (r *competitionsRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS competitions (id INTEGER PRIMARY KEY, sport_id INTEGER, name TEXT)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	id := 0
+	for i, sport := range seedSports {
+		for _, name := range sport.competitions {
+			id++
+
+			statement, err = r.db.Prepare(`INSERT OR IGNORE INTO competitions(id, sport_id, name) VALUES (?,?,?)`)
+			if err == nil {
+				_, err = statement.Exec(id, i+1, name)
+			}
+		}
+	}
+
+	return err
+}
+
+func (r *eventsRepo) seed() error {
+	// competitionSports holds the index in seedSports of each seeded competition, by id - 1.
+	var competitionSports []int
+	for i, sport := range seedSports {
+		for range sport.competitions {
+			competitionSports = append(competitionSports, i)
+		}
+	}
+
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS events (id INTEGER PRIMARY KEY, competition_id INTEGER, name TEXT, city_address TEXT, advertised_start_time DATETIME, visible INTEGER NOT NULL DEFAULT 0, status INTEGER)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	// Events kept from before sports were modelled have no competition.
+	if err == nil {
+		err = addColumnIfMissing(r.db, "events", "competition_id", "INTEGER")
+	}
+
+	// Databases created before events could be hidden need the column added.
+	if err == nil {
+		err = addColumnIfMissing(r.db, "events", "visible", "INTEGER NOT NULL DEFAULT 0")
+	}
+
+	// Likewise for the lifecycle status.
+	if err == nil {
+		err = addColumnIfMissing(r.db, "events", "status", "INTEGER")
+	}
+
+	for i := 1; i <= seedEvents; i++ {
+		competition := faker.Number().NumberInt(2)%len(competitionSports) + 1
+
+		// Events are named after their sides, which the participants are seeded from.
+		name := faker.Team().Name() + " v " + faker.Team().Name()
+		if seedSports[competitionSports[competition-1]].individual {
+			name = faker.Name().Name() + " v " + faker.Name().Name()
+		}
+
+		statement, err = r.db.Prepare(`INSERT OR IGNORE INTO events(id, competition_id, name, city_address, advertised_start_time, visible) VALUES (?,?,?,?,?,?)`)
 		if err == nil {
 			_, err = statement.Exec(
 				i,
-				faker.RandomChoice(sportsChoices),
+				competition,
+				name,
 				faker.Address().City(),
-				faker.Number().Between(0, 1000),
 				faker.Time().Between(time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 2)).Format(time.RFC3339),
+				faker.Number().Between(0, 1),
 			)
 		}
 	}
 
 	return err
 }
+
+func (r *participantsRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS participants (id INTEGER PRIMARY KEY, event_id INTEGER, name TEXT, type INTEGER, role INTEGER, country TEXT)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+	if err != nil {
+		return err
+	}
+
+	// The seeded events are named "Home v Away", so each side becomes a participant.
+	type seedEvent struct {
+		id, sportID int64
+		name        string
+	}
+
+	rows, err := r.db.Query(`SELECT e.id, e.name, COALESCE(c.sport_id, 0) FROM events e LEFT JOIN competitions c ON c.id = e.competition_id WHERE e.id <= ? ORDER BY e.id`, seedEvents)
+	if err != nil {
+		return err
+	}
+
+	var events []seedEvent
+	for rows.Next() {
+		var event seedEvent
+		if err := rows.Scan(&event.id, &event.name, &event.sportID); err != nil {
+			rows.Close()
+			return err
+		}
+
+		events = append(events, event)
+	}
+	rows.Close()
+
+	for _, event := range events {
+		sides := strings.SplitN(event.name, " v ", 2)
+		if len(sides) != 2 {
+			continue
+		}
+
+		participantType := sports.ParticipantType_TEAM
+		if event.sportID >= 1 && int(event.sportID) <= len(seedSports) && seedSports[event.sportID-1].individual {
+			participantType = sports.ParticipantType_INDIVIDUAL
+		}
+
+		for i, side := range sides {
+			role := sports.ParticipantRole_HOME
+			if i == 1 {
+				role = sports.ParticipantRole_AWAY
+			}
+
+			statement, err = r.db.Prepare(`INSERT OR IGNORE INTO participants(id, event_id, name, type, role, country) VALUES (?,?,?,?,?,?)`)
+			if err == nil {
+				_, err = statement.Exec((event.id-1)*2+int64(i)+1, event.id, side, participantType, role, faker.Address().Country())
+			}
+		}
+	}
+
+	return err
+}
+
+func (r *marketsRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS markets (id INTEGER PRIMARY KEY, event_id INTEGER, name TEXT, type INTEGER, line REAL, in_play INTEGER NOT NULL DEFAULT 0, status INTEGER)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	id := 0
+	for event := 1; event <= seedEvents; event++ {
+		for _, market := range seedMarkets {
+			id++
+
+			// Lines and totals end in .5 so they cannot be tied.
+			var line float64
+			switch market.marketType {
+			case sports.MarketType_LINE:
+				line = -float64(faker.Number().NumberInt(2)%13) - 0.5
+			case sports.MarketType_TOTAL:
+				line = float64(faker.Number().NumberInt(3)%181+20) + 0.5
+			}
+
+			statement, err = r.db.Prepare(`INSERT OR IGNORE INTO markets(id, event_id, name, type, line, in_play) VALUES (?,?,?,?,?,?)`)
+			if err == nil {
+				_, err = statement.Exec(id, event, market.name, market.marketType, line, faker.Number().Between(0, 1))
+			}
+		}
+	}
+
+	return err
+}
+
+func (r *selectionsRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS selections (id INTEGER PRIMARY KEY, market_id INTEGER, name TEXT, price REAL, status INTEGER)`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	id, market := 0, 0
+	for event := 1; event <= seedEvents; event++ {
+		for _, seed := range seedMarkets {
+			market++
+
+			for _, name := range seed.selections {
+				id++
+
+				statement, err = r.db.Prepare(`INSERT OR IGNORE INTO selections(id, market_id, name, price) VALUES (?,?,?,?)`)
+				if err == nil {
+					_, err = statement.Exec(id, market, name, float64(faker.Number().NumberInt(3)%400+110)/100)
+				}
+			}
+		}
+	}
+
+	return err
+}
+
+// Scores are pushed by live score feeds through PushScores, so no dummy scores are seeded.
+func (r *scoresRepo) seed() error {
+	statement, err := r.db.Prepare(`CREATE TABLE IF NOT EXISTS scores (event_id INTEGER, period INTEGER, home INTEGER, away INTEGER, updated_at DATETIME, PRIMARY KEY (event_id, period))`)
+	if err == nil {
+		_, err = statement.Exec()
+	}
+
+	return err
+}
+
+// renameTableIfHasColumn renames a table left over from an older schema, recognised by a column it
+// had, keeping its rows. It fails if a table of the new name already exists.
+func renameTableIfHasColumn(db *sql.DB, table, column, name string) error {
+	var found int
+
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&found)
+	if err != nil || found == 0 {
+		return err
+	}
+
+	_, err = db.Exec(`ALTER TABLE ` + table + ` RENAME TO ` + name)
+
+	return err
+}
+
+// addColumnIfMissing adds a column to a table created before the column existed.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	var found int
+
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&found)
+	if err != nil || found != 0 {
+		return err
+	}
+
+	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + definition)
+
+	return err
+}
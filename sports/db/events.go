@@ -0,0 +1,288 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"sports/proto/sports"
+
+	"github.com/golang/protobuf/ptypes"
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EventsRepo provides repository access to sport events.
+type EventsRepo interface {
+	// Init will initialise our events repository.
+	Init() error
+
+	// List will return a list of events.
+	List(ctx context.Context, filter *sports.ListEventsRequestFilter) ([]*sports.Event, error)
+
+	// Get will return one event.
+	Get(ctx context.Context, id int64) (*sports.Event, error)
+
+	// UpdateStatus stores a new status for an event, provided it is still in status from.
+	UpdateStatus(ctx context.Context, id int64, from sports.EventStatus, to sports.EventStatus) (*sports.Event, error)
+}
+
+type eventsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewEventsRepo creates a new events repository
+func NewEventsRepo(db *sql.DB) EventsRepo {
+	return &eventsRepo{db: db}
+}
+
+// Init prepares the event repository dummy data.
+func (r *eventsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with some dummy events.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *eventsRepo) List(ctx context.Context, filter *sports.ListEventsRequestFilter) ([]*sports.Event, error) {
+	ctx, done := observeQuery(ctx, "events", "List")
+	defer done()
+
+	var (
+		err   error
+		query string
+		args  []interface{}
+	)
+
+	if filter != nil && filter.StartAfter != nil && filter.StartBefore != nil &&
+		!filter.StartAfter.AsTime().Before(filter.StartBefore.AsTime()) {
+		return nil, status.Error(codes.InvalidArgument, "start_after must be before start_before")
+	}
+
+	if filter != nil && filter.MinParticipants != nil && filter.MaxParticipants != nil &&
+		*filter.MinParticipants > *filter.MaxParticipants {
+		return nil, status.Error(codes.InvalidArgument, "min_participants must not be greater than max_participants")
+	}
+
+	query = getSportEventQueries()[sportEventsList]
+
+	query, args = r.applyFilter(query, filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanEvents(rows)
+}
+
+func (r *eventsRepo) applyFilter(query string, filter *sports.ListEventsRequestFilter) (string, []interface{}) {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+
+	if filter == nil {
+		return query + " ORDER BY e.id ASC", args
+	}
+
+	if len(filter.Ids) > 0 {
+		clauses = append(clauses, "e.id IN ("+strings.Repeat("?,", len(filter.Ids)-1)+"?)")
+
+		for _, EventId := range filter.Ids {
+			args = append(args, EventId)
+		}
+	}
+
+	if len(filter.SportIds) > 0 {
+		clauses = append(clauses, "c.sport_id IN ("+strings.Repeat("?,", len(filter.SportIds)-1)+"?)")
+
+		for _, sportID := range filter.SportIds {
+			args = append(args, sportID)
+		}
+	}
+
+	if len(filter.CompetitionIds) > 0 {
+		clauses = append(clauses, "e.competition_id IN ("+strings.Repeat("?,", len(filter.CompetitionIds)-1)+"?)")
+
+		for _, competitionID := range filter.CompetitionIds {
+			args = append(args, competitionID)
+		}
+	}
+
+	if filter.Visible != nil {
+		clauses = append(clauses, "e.visible = ?")
+		args = append(args, *filter.Visible)
+	}
+
+	if filter.StartAfter != nil {
+		clauses = append(clauses, startTimeKey+" >= julianday(?)")
+		args = append(args, filter.StartAfter.AsTime().Format(time.RFC3339Nano))
+	}
+
+	if filter.StartBefore != nil {
+		clauses = append(clauses, startTimeKey+" < julianday(?)")
+		args = append(args, filter.StartBefore.AsTime().Format(time.RFC3339Nano))
+	}
+
+	if len(filter.Statuses) > 0 {
+		clauses = append(clauses, eventStatus+" IN ("+strings.Repeat("?,", len(filter.Statuses)-1)+"?)")
+
+		for _, statusFilter := range filter.Statuses {
+			args = append(args, statusFilter)
+		}
+	}
+
+	if filter.CityAddress != "" {
+		clauses = append(clauses, "e.city_address = ? COLLATE NOCASE")
+		args = append(args, filter.CityAddress)
+	}
+
+	if filter.MinParticipants != nil {
+		clauses = append(clauses, numOfParticipants+" >= ?")
+		args = append(args, *filter.MinParticipants)
+	}
+
+	if filter.MaxParticipants != nil {
+		clauses = append(clauses, numOfParticipants+" <= ?")
+		args = append(args, *filter.MaxParticipants)
+	}
+
+	// LIKE ignores ASCII case by default; the search text is escaped so % and _ match literally.
+	if filter.Name != "" {
+		clauses = append(clauses, `e.name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+
+	if len(clauses) != 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	query += " ORDER BY " + orderEvents(filter)
+
+	return query, args
+}
+
+// likeEscaper escapes the LIKE wildcards in search text, along with the escape character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// orderEvents returns the ORDER BY terms for a filter. Only the order field and direction are
+// taken from the request, never raw SQL. id breaks ties so the order is stable.
+func orderEvents(filter *sports.ListEventsRequestFilter) string {
+	if filter.OrderField == nil && filter.OrderBy == nil {
+		return "e.id ASC"
+	}
+
+	direction := "ASC"
+	if filter.OrderBy != nil && *filter.OrderBy == sports.OrderBy_DESC {
+		direction = "DESC"
+	}
+
+	column := startTimeKey
+	switch filter.GetOrderField() {
+	case sports.EventOrderField_NAME:
+		column = "e.name"
+	case sports.EventOrderField_NUM_OF_PARTICIPANTS:
+		column = numOfParticipants
+	}
+
+	return column + " " + direction + ", e.id " + direction
+}
+
+func (m *eventsRepo) scanEvents(
+	rows *sql.Rows,
+) ([]*sports.Event, error) {
+	var events []*sports.Event
+
+	for rows.Next() {
+		var event sports.Event
+		var advertisedStart time.Time
+
+		if err := rows.Scan(&event.Id, &event.Name, &event.CityAddress, &event.NumOfParticipants, &advertisedStart, &event.SportId, &event.CompetitionId, &event.Visible, &event.Status); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		ts, err := ptypes.TimestampProto(advertisedStart)
+		if err != nil {
+			return nil, err
+		}
+
+		event.AdvertisedStartTime = ts
+
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *eventsRepo) Get(ctx context.Context, id int64) (*sports.Event, error) {
+	ctx, done := observeQuery(ctx, "events", "Get")
+	defer done()
+
+	var (
+		event           sports.Event
+		advertisedStart time.Time
+	)
+
+	row := r.db.QueryRowContext(ctx, getSportEventQueries()[sportEventById], id)
+
+	if err := row.Scan(&event.Id, &event.Name, &event.CityAddress, &event.NumOfParticipants, &advertisedStart, &event.SportId, &event.CompetitionId, &event.Visible, &event.Status); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "Event not found")
+		}
+
+		return nil, err
+	}
+
+	ts, err := ptypes.TimestampProto(advertisedStart)
+	if err != nil {
+		return nil, err
+	}
+
+	event.AdvertisedStartTime = ts
+
+	return &event, nil
+}
+
+func (r *eventsRepo) UpdateStatus(ctx context.Context, id int64, from sports.EventStatus, to sports.EventStatus) (*sports.Event, error) {
+	ctx, done := observeQuery(ctx, "events", "UpdateStatus")
+	defer done()
+
+	res, err := r.db.ExecContext(ctx, getSportEventQueries()[sportEventUpdateStatus], to, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	// Either the event is gone or its status moved on since it was read.
+	if updated == 0 {
+		if _, err := r.Get(ctx, id); err != nil {
+			return nil, err
+		}
+
+		return nil, status.Error(codes.Aborted, "Event status was changed concurrently")
+	}
+
+	return r.Get(ctx, id)
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"sports/proto/sports"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MarketsRepo provides repository access to the betting markets of sport events.
+type MarketsRepo interface {
+	// Init will initialise our markets repository.
+	Init() error
+
+	// List will return the markets of an event, without their selections.
+	List(ctx context.Context, eventID int64) ([]*sports.Market, error)
+
+	// Get will return one market, without its selections.
+	Get(ctx context.Context, id int64) (*sports.Market, error)
+}
+
+type marketsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewMarketsRepo creates a new markets repository
+func NewMarketsRepo(db *sql.DB) MarketsRepo {
+	return &marketsRepo{db: db}
+}
+
+// Init prepares the market repository dummy data.
+func (r *marketsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with some dummy markets.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *marketsRepo) List(ctx context.Context, eventID int64) ([]*sports.Market, error) {
+	ctx, done := observeQuery(ctx, "markets", "List")
+	defer done()
+
+	rows, err := r.db.QueryContext(ctx, getMarketQueries()[marketsByEvent], eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var markets []*sports.Market
+
+	for rows.Next() {
+		var market sports.Market
+
+		if err := rows.Scan(&market.Id, &market.EventId, &market.Name, &market.Type, &market.Line, &market.InPlay, &market.Status); err != nil {
+			return nil, err
+		}
+
+		markets = append(markets, &market)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return markets, nil
+}
+
+func (r *marketsRepo) Get(ctx context.Context, id int64) (*sports.Market, error) {
+	ctx, done := observeQuery(ctx, "markets", "Get")
+	defer done()
+
+	var market sports.Market
+
+	row := r.db.QueryRowContext(ctx, getMarketQueries()[marketById], id)
+
+	if err := row.Scan(&market.Id, &market.EventId, &market.Name, &market.Type, &market.Line, &market.InPlay, &market.Status); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "Market not found")
+		}
+
+		return nil, err
+	}
+
+	return &market, nil
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"sports/proto/sports"
+)
+
+// ParticipantsRepo provides repository access to the teams and individuals taking part in
+// sport events.
+type ParticipantsRepo interface {
+	// Init will initialise our participants repository.
+	Init() error
+
+	// List will return the participants of an event, home side first.
+	List(ctx context.Context, eventID int64) ([]*sports.Participant, error)
+}
+
+type participantsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewParticipantsRepo creates a new participants repository
+func NewParticipantsRepo(db *sql.DB) ParticipantsRepo {
+	return &participantsRepo{db: db}
+}
+
+// Init prepares the participant repository dummy data.
+func (r *participantsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with the sides of the dummy events.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *participantsRepo) List(ctx context.Context, eventID int64) ([]*sports.Participant, error) {
+	ctx, done := observeQuery(ctx, "participants", "List")
+	defer done()
+
+	rows, err := r.db.QueryContext(ctx, getParticipantQueries()[participantsByEvent], eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var participants []*sports.Participant
+
+	for rows.Next() {
+		var participant sports.Participant
+
+		if err := rows.Scan(&participant.Id, &participant.EventId, &participant.Name, &participant.Type, &participant.Role, &participant.Country); err != nil {
+			return nil, err
+		}
+
+		participants = append(participants, &participant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return participants, nil
+}
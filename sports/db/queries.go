@@ -1,19 +1,218 @@
 package db
 
 const (
-	sportEventsList = "list"
+	sportEventsList        = "list"
+	sportEventById         = "getById"
+	sportEventUpdateStatus = "updateStatus"
 )
 
+// startTimeKey normalises advertised_start_time for comparison and ordering, as rows may be
+// stored in either RFC3339 or SQLite's own datetime layout.
+const startTimeKey = "julianday(e.advertised_start_time)"
+
+// numOfParticipants counts the participants linked to an event. Databases created before
+// participants were modelled still have a num_of_participants column, which is no longer read.
+const numOfParticipants = "(SELECT COUNT(*) FROM participants p WHERE p.event_id = e.id)"
+
+// eventStatus resolves an event's sports.EventStatus. The stored lifecycle status is
+// authoritative, and an event with none is SCHEDULED (0): an event only goes LIVE when it is moved
+// there, so one nobody follows up is never left LIVE for good.
+const eventStatus = "COALESCE(e.status, 0)"
+
+// Events without a competition, from before sports were modelled, are listed with a sport_id
+// and competition_id of 0.
 func getSportEventQueries() map[string]string {
 	return map[string]string{
 		sportEventsList: `
+			SELECT 
+				e.id, 
+				e.name, 
+				e.city_address, 
+				` + numOfParticipants + ` AS num_of_participants, 
+				e.advertised_start_time, 
+				COALESCE(c.sport_id, 0), 
+				COALESCE(e.competition_id, 0), 
+				e.visible, 
+				` + eventStatus + ` AS status
+			FROM events e
+			LEFT JOIN competitions c ON c.id = e.competition_id
+		`,
+		sportEventById: `
+			SELECT 
+				e.id, 
+				e.name, 
+				e.city_address, 
+				` + numOfParticipants + ` AS num_of_participants, 
+				e.advertised_start_time, 
+				COALESCE(c.sport_id, 0), 
+				COALESCE(e.competition_id, 0), 
+				e.visible, 
+				` + eventStatus + ` AS status
+			FROM events e
+			LEFT JOIN competitions c ON c.id = e.competition_id
+			WHERE e.id = $1
+		`,
+		// Only updates when the event is still in the status the transition was checked against.
+		sportEventUpdateStatus: `
+			UPDATE events AS e
+			SET status = ?
+			WHERE e.id = ? AND ` + eventStatus + ` = ?
+		`,
+	}
+}
+
+const (
+	sportsList = "list"
+)
+
+func getSportQueries() map[string]string {
+	return map[string]string{
+		sportsList: `
 			SELECT 
 				id, 
-				name, 
-				city_address, 
-				num_of_participants, 
-				advertised_start_time
+				name 
 			FROM sports
+			ORDER BY name ASC, id ASC
+		`,
+	}
+}
+
+const (
+	competitionsList = "list"
+)
+
+func getCompetitionQueries() map[string]string {
+	return map[string]string{
+		competitionsList: `
+			SELECT 
+				id, 
+				sport_id, 
+				name 
+			FROM competitions
+		`,
+	}
+}
+
+const (
+	marketsByEvent = "listByEvent"
+	marketById     = "getById"
+)
+
+// marketStatus resolves a market's sports.MarketStatus. A stored CLOSED (2) or SETTLED (3) status
+// always wins. Otherwise the event comes first: markets are CLOSED while their event is FINISHED (3)
+// or CANCELLED (4), and SUSPENDED (1) while it is SUSPENDED (2). Then a stored SUSPENDED status
+// wins, and markets that are not in-play are SUSPENDED while their event is LIVE (1), OPEN
+// otherwise.
+const marketStatus = "CASE WHEN m.status IN (2, 3) THEN m.status" +
+	" WHEN " + eventStatus + " IN (3, 4) THEN 2" +
+	" WHEN " + eventStatus + " = 2 THEN 1" +
+	" WHEN m.status IS NOT NULL AND m.status != 0 THEN m.status" +
+	" WHEN m.in_play = 0 AND " + eventStatus + " = 1 THEN 1" +
+	" ELSE 0 END"
+
+func getMarketQueries() map[string]string {
+	return map[string]string{
+		marketsByEvent: `
+			SELECT 
+				m.id, 
+				m.event_id, 
+				m.name, 
+				m.type, 
+				m.line, 
+				m.in_play, 
+				` + marketStatus + ` AS status
+			FROM markets m
+			JOIN events e ON e.id = m.event_id
+			WHERE m.event_id = $1
+			ORDER BY m.id ASC
+		`,
+		marketById: `
+			SELECT 
+				m.id, 
+				m.event_id, 
+				m.name, 
+				m.type, 
+				m.line, 
+				m.in_play, 
+				` + marketStatus + ` AS status
+			FROM markets m
+			JOIN events e ON e.id = m.event_id
+			WHERE m.id = $1
+		`,
+	}
+}
+
+const (
+	selectionsList = "list"
+)
+
+// selectionStatus resolves a selection's sports.SelectionStatus. A stored WON (2) or LOST (3)
+// status always wins. Otherwise selections are SUSPENDED (1) while their market is anything but
+// OPEN, and follow their stored status, OPEN when there is none, while it is.
+const selectionStatus = "CASE WHEN s.status IN (2, 3) THEN s.status WHEN " + marketStatus + " != 0 THEN 1 ELSE COALESCE(s.status, 0) END"
+
+func getSelectionQueries() map[string]string {
+	return map[string]string{
+		selectionsList: `
+			SELECT 
+				s.id, 
+				s.market_id, 
+				s.name, 
+				s.price, 
+				` + selectionStatus + ` AS status
+			FROM selections s
+			JOIN markets m ON m.id = s.market_id
+			JOIN events e ON e.id = m.event_id
+		`,
+	}
+}
+
+const (
+	scoresList   = "list"
+	scoresUpsert = "upsert"
+)
+
+func getScoreQueries() map[string]string {
+	return map[string]string{
+		scoresList: `
+			SELECT 
+				event_id, 
+				period, 
+				home, 
+				away, 
+				updated_at 
+			FROM scores
+		`,
+		// Only replaces the stored score of a period with one recorded at the same time or later,
+		// so updates the feed delivers out of order cannot roll a score back.
+		scoresUpsert: `
+			INSERT INTO scores(event_id, period, home, away, updated_at) VALUES (?,?,?,?,?)
+			ON CONFLICT(event_id, period) DO UPDATE SET
+				home = excluded.home,
+				away = excluded.away,
+				updated_at = excluded.updated_at
+			WHERE julianday(excluded.updated_at) >= julianday(scores.updated_at)
+		`,
+	}
+}
+
+const (
+	participantsByEvent = "listByEvent"
+)
+
+func getParticipantQueries() map[string]string {
+	return map[string]string{
+		participantsByEvent: `
+			SELECT 
+				id, 
+				event_id, 
+				name, 
+				type, 
+				role, 
+				country 
+			FROM participants
+			WHERE event_id = $1
+			ORDER BY role ASC, id ASC
 		`,
 	}
 }
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"sports/proto/sports"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+// ScoresRepo provides repository access to the live scores of sport events, stored as the
+// latest score of each period.
+type ScoresRepo interface {
+	// Init will initialise our scores repository.
+	Init() error
+
+	// List will return the period scores of the given events, ordered by event and period.
+	List(ctx context.Context, eventIDs []int64) ([]*sports.ScoreUpdate, error)
+
+	// Upsert will store the score of a period, reporting false when a later score is already stored.
+	Upsert(ctx context.Context, update *sports.ScoreUpdate) (bool, error)
+}
+
+type scoresRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewScoresRepo creates a new scores repository
+func NewScoresRepo(db *sql.DB) ScoresRepo {
+	return &scoresRepo{db: db}
+}
+
+// Init prepares the score repository.
+func (r *scoresRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *scoresRepo) List(ctx context.Context, eventIDs []int64) ([]*sports.ScoreUpdate, error) {
+	ctx, done := observeQuery(ctx, "scores", "List")
+	defer done()
+
+	if len(eventIDs) == 0 {
+		return nil, nil
+	}
+
+	query := getScoreQueries()[scoresList] +
+		" WHERE event_id IN (" + strings.Repeat("?,", len(eventIDs)-1) + "?) ORDER BY event_id ASC, period ASC"
+
+	args := make([]interface{}, 0, len(eventIDs))
+	for _, eventID := range eventIDs {
+		args = append(args, eventID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []*sports.ScoreUpdate
+
+	for rows.Next() {
+		var score sports.ScoreUpdate
+		var updatedAt time.Time
+
+		if err := rows.Scan(&score.EventId, &score.Period, &score.Home, &score.Away, &updatedAt); err != nil {
+			return nil, err
+		}
+
+		ts, err := ptypes.TimestampProto(updatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		score.Timestamp = ts
+
+		scores = append(scores, &score)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+func (r *scoresRepo) Upsert(ctx context.Context, update *sports.ScoreUpdate) (bool, error) {
+	ctx, done := observeQuery(ctx, "scores", "Upsert")
+	defer done()
+
+	res, err := r.db.ExecContext(ctx, getScoreQueries()[scoresUpsert], update.EventId, update.Period, update.Home, update.Away, update.Timestamp.AsTime().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return stored != 0, nil
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"sports/proto/sports"
+)
+
+// SelectionsRepo provides repository access to the selections of betting markets.
+type SelectionsRepo interface {
+	// Init will initialise our selections repository.
+	Init() error
+
+	// List will return the selections of the given markets, ordered by market and id.
+	List(ctx context.Context, marketIDs []int64) ([]*sports.Selection, error)
+}
+
+type selectionsRepo struct {
+	db   *sql.DB
+	init sync.Once
+}
+
+// NewSelectionsRepo creates a new selections repository
+func NewSelectionsRepo(db *sql.DB) SelectionsRepo {
+	return &selectionsRepo{db: db}
+}
+
+// Init prepares the selection repository dummy data.
+func (r *selectionsRepo) Init() error {
+	var err error
+
+	r.init.Do(func() {
+		// For test/example purposes, we seed the DB with some dummy selections.
+		err = r.seed()
+	})
+
+	return err
+}
+
+func (r *selectionsRepo) List(ctx context.Context, marketIDs []int64) ([]*sports.Selection, error) {
+	ctx, done := observeQuery(ctx, "selections", "List")
+	defer done()
+
+	if len(marketIDs) == 0 {
+		return nil, nil
+	}
+
+	query := getSelectionQueries()[selectionsList] +
+		" WHERE s.market_id IN (" + strings.Repeat("?,", len(marketIDs)-1) + "?) ORDER BY s.market_id ASC, s.id ASC"
+
+	args := make([]interface{}, 0, len(marketIDs))
+	for _, marketID := range marketIDs {
+		args = append(args, marketID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var selections []*sports.Selection
+
+	for rows.Next() {
+		var selection sports.Selection
+
+		if err := rows.Scan(&selection.Id, &selection.MarketId, &selection.Name, &selection.Price, &selection.Status); err != nil {
+			return nil, err
+		}
+
+		selections = append(selections, &selection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return selections, nil
+}
@@ -1,23 +1,20 @@
 package db
 
 import (
+	"context"
 	"database/sql"
-	"strings"
 	"sync"
-	"time"
 
 	"sports/proto/sports"
-
-	"github.com/golang/protobuf/ptypes"
-	_ "github.com/mattn/go-sqlite3"
 )
 
+// SportsRepo provides repository access to sports.
 type SportsRepo interface {
 	// Init will initialise our sports repository.
 	Init() error
 
-	// List will return a list of events.
-	List(filter *sports.ListEventsRequestFilter) ([]*sports.Event, error)
+	// List will return every sport.
+	List(ctx context.Context) ([]*sports.Sport, error)
 }
 
 type sportsRepo struct {
@@ -30,88 +27,43 @@ func NewSportsRepo(db *sql.DB) SportsRepo {
 	return &sportsRepo{db: db}
 }
 
-// Init prepares the event repository dummy data.
+// Init prepares the sport repository dummy data.
 func (r *sportsRepo) Init() error {
 	var err error
 
 	r.init.Do(func() {
-		// For test/example purposes, we seed the DB with some dummy events.
+		// For test/example purposes, we seed the DB with some dummy sports.
 		err = r.seed()
 	})
 
 	return err
 }
 
-func (r *sportsRepo) List(filter *sports.ListEventsRequestFilter) ([]*sports.Event, error) {
-	var (
-		err   error
-		query string
-		args  []interface{}
-	)
-
-	query = getSportEventQueries()[sportEventsList]
-
-	query, args = r.applyFilter(query, filter)
+func (r *sportsRepo) List(ctx context.Context) ([]*sports.Sport, error) {
+	ctx, done := observeQuery(ctx, "sports", "List")
+	defer done()
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, getSportQueries()[sportsList])
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return r.scanSports(rows)
-}
-
-func (r *sportsRepo) applyFilter(query string, filter *sports.ListEventsRequestFilter) (string, []interface{}) {
-	var (
-		clauses []string
-		args    []interface{}
-	)
-
-	if filter == nil {
-		return query, args
-	}
-
-	if len(filter.Ids) > 0 {
-		clauses = append(clauses, "id IN ("+strings.Repeat("?,", len(filter.Ids)-1)+"?)")
-
-		for _, EventId := range filter.Ids {
-			args = append(args, EventId)
-		}
-	}
-
-	if len(clauses) != 0 {
-		query += " WHERE " + strings.Join(clauses, " AND ")
-	}
-
-	return query, args
-}
-
-func (m *sportsRepo) scanSports(
-	rows *sql.Rows,
-) ([]*sports.Event, error) {
-	var events []*sports.Event
+	var list []*sports.Sport
 
 	for rows.Next() {
-		var event sports.Event
-		var advertisedStart time.Time
-
-		if err := rows.Scan(&event.Id, &event.Name, &event.CityAddress, &event.NumOfParticipants, &advertisedStart); err != nil {
-			if err == sql.ErrNoRows {
-				return nil, nil
-			}
+		var sport sports.Sport
 
+		if err := rows.Scan(&sport.Id, &sport.Name); err != nil {
 			return nil, err
 		}
 
-		ts, err := ptypes.TimestampProto(advertisedStart)
-		if err != nil {
-			return nil, err
-		}
-
-		event.AdvertisedStartTime = ts
+		list = append(list, &sport)
+	}
 
-		events = append(events, &event)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return events, nil
+	return list, nil
 }
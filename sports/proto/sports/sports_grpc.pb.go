@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -23,6 +24,33 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SportsClient interface {
 	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsReponse, error)
+	// Get a single sport event by its id
+	GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*Event, error)
+	// UpdateEventStatus moves a sport event to a new lifecycle status, rejecting illegal transitions.
+	UpdateEventStatus(ctx context.Context, in *UpdateEventStatusRequest, opts ...grpc.CallOption) (*Event, error)
+	// ListSports will return every sport, the top level of the navigation tree.
+	ListSports(ctx context.Context, in *ListSportsRequest, opts ...grpc.CallOption) (*ListSportsResponse, error)
+	// ListCompetitions will return the competitions, or leagues, of sports.
+	ListCompetitions(ctx context.Context, in *ListCompetitionsRequest, opts ...grpc.CallOption) (*ListCompetitionsResponse, error)
+	// ListMarkets will return the betting markets of a sport event, with their selections.
+	ListMarkets(ctx context.Context, in *ListMarketsRequest, opts ...grpc.CallOption) (*ListMarketsResponse, error)
+	// GetMarket will return a single betting market, with its selections.
+	GetMarket(ctx context.Context, in *GetMarketRequest, opts ...grpc.CallOption) (*Market, error)
+	// ListParticipants will return the teams or individuals taking part in a sport event.
+	ListParticipants(ctx context.Context, in *ListParticipantsRequest, opts ...grpc.CallOption) (*ListParticipantsResponse, error)
+	// PushScores ingests a live score feed, one update per period score. It is meant for feed
+	// adapters, so it is only served over gRPC.
+	PushScores(ctx context.Context, opts ...grpc.CallOption) (Sports_PushScoresClient, error)
+	// WatchEvent sends a sport event, then sends it again each time it changes. The gateway serves
+	// it as Server-Sent Events on GET /v1/sports-event/{id}/watch rather than through a generated binding.
+	WatchEvent(ctx context.Context, in *WatchEventRequest, opts ...grpc.CallOption) (Sports_WatchEventClient, error)
+	// WatchEvents will stream changes to the sport events matching a filter. It is only served over
+	// gRPC.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (Sports_WatchEventsClient, error)
+	// WatchChanges streams a signal each time sport events change, without the events themselves.
+	// A first signal is sent once watching. It is only served over gRPC, the gateway uses it to
+	// drop cached responses early.
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Sports_WatchChangesClient, error)
 }
 
 type sportsClient struct {
@@ -42,11 +70,231 @@ func (c *sportsClient) ListEvents(ctx context.Context, in *ListEventsRequest, op
 	return out, nil
 }
 
+func (c *sportsClient) GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/sports.Sports/GetEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sportsClient) UpdateEventStatus(ctx context.Context, in *UpdateEventStatusRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/sports.Sports/UpdateEventStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sportsClient) ListSports(ctx context.Context, in *ListSportsRequest, opts ...grpc.CallOption) (*ListSportsResponse, error) {
+	out := new(ListSportsResponse)
+	err := c.cc.Invoke(ctx, "/sports.Sports/ListSports", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sportsClient) ListCompetitions(ctx context.Context, in *ListCompetitionsRequest, opts ...grpc.CallOption) (*ListCompetitionsResponse, error) {
+	out := new(ListCompetitionsResponse)
+	err := c.cc.Invoke(ctx, "/sports.Sports/ListCompetitions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sportsClient) ListMarkets(ctx context.Context, in *ListMarketsRequest, opts ...grpc.CallOption) (*ListMarketsResponse, error) {
+	out := new(ListMarketsResponse)
+	err := c.cc.Invoke(ctx, "/sports.Sports/ListMarkets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sportsClient) GetMarket(ctx context.Context, in *GetMarketRequest, opts ...grpc.CallOption) (*Market, error) {
+	out := new(Market)
+	err := c.cc.Invoke(ctx, "/sports.Sports/GetMarket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sportsClient) ListParticipants(ctx context.Context, in *ListParticipantsRequest, opts ...grpc.CallOption) (*ListParticipantsResponse, error) {
+	out := new(ListParticipantsResponse)
+	err := c.cc.Invoke(ctx, "/sports.Sports/ListParticipants", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sportsClient) PushScores(ctx context.Context, opts ...grpc.CallOption) (Sports_PushScoresClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sports_ServiceDesc.Streams[0], "/sports.Sports/PushScores", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sportsPushScoresClient{stream}
+	return x, nil
+}
+
+type Sports_PushScoresClient interface {
+	Send(*ScoreUpdate) error
+	CloseAndRecv() (*PushScoresResponse, error)
+	grpc.ClientStream
+}
+
+type sportsPushScoresClient struct {
+	grpc.ClientStream
+}
+
+func (x *sportsPushScoresClient) Send(m *ScoreUpdate) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sportsPushScoresClient) CloseAndRecv() (*PushScoresResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PushScoresResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sportsClient) WatchEvent(ctx context.Context, in *WatchEventRequest, opts ...grpc.CallOption) (Sports_WatchEventClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sports_ServiceDesc.Streams[1], "/sports.Sports/WatchEvent", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sportsWatchEventClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sports_WatchEventClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type sportsWatchEventClient struct {
+	grpc.ClientStream
+}
+
+func (x *sportsWatchEventClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sportsClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (Sports_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sports_ServiceDesc.Streams[2], "/sports.Sports/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sportsWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sports_WatchEventsClient interface {
+	Recv() (*EventChange, error)
+	grpc.ClientStream
+}
+
+type sportsWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *sportsWatchEventsClient) Recv() (*EventChange, error) {
+	m := new(EventChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sportsClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Sports_WatchChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sports_ServiceDesc.Streams[3], "/sports.Sports/WatchChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sportsWatchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sports_WatchChangesClient interface {
+	Recv() (*emptypb.Empty, error)
+	grpc.ClientStream
+}
+
+type sportsWatchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *sportsWatchChangesClient) Recv() (*emptypb.Empty, error) {
+	m := new(emptypb.Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // SportsServer is the server API for Sports service.
 // All implementations should embed UnimplementedSportsServer
 // for forward compatibility
 type SportsServer interface {
 	ListEvents(context.Context, *ListEventsRequest) (*ListEventsReponse, error)
+	// Get a single sport event by its id
+	GetEvent(context.Context, *GetEventRequest) (*Event, error)
+	// UpdateEventStatus moves a sport event to a new lifecycle status, rejecting illegal transitions.
+	UpdateEventStatus(context.Context, *UpdateEventStatusRequest) (*Event, error)
+	// ListSports will return every sport, the top level of the navigation tree.
+	ListSports(context.Context, *ListSportsRequest) (*ListSportsResponse, error)
+	// ListCompetitions will return the competitions, or leagues, of sports.
+	ListCompetitions(context.Context, *ListCompetitionsRequest) (*ListCompetitionsResponse, error)
+	// ListMarkets will return the betting markets of a sport event, with their selections.
+	ListMarkets(context.Context, *ListMarketsRequest) (*ListMarketsResponse, error)
+	// GetMarket will return a single betting market, with its selections.
+	GetMarket(context.Context, *GetMarketRequest) (*Market, error)
+	// ListParticipants will return the teams or individuals taking part in a sport event.
+	ListParticipants(context.Context, *ListParticipantsRequest) (*ListParticipantsResponse, error)
+	// PushScores ingests a live score feed, one update per period score. It is meant for feed
+	// adapters, so it is only served over gRPC.
+	PushScores(Sports_PushScoresServer) error
+	// WatchEvent sends a sport event, then sends it again each time it changes. The gateway serves
+	// it as Server-Sent Events on GET /v1/sports-event/{id}/watch rather than through a generated binding.
+	WatchEvent(*WatchEventRequest, Sports_WatchEventServer) error
+	// WatchEvents will stream changes to the sport events matching a filter. It is only served over
+	// gRPC.
+	WatchEvents(*WatchEventsRequest, Sports_WatchEventsServer) error
+	// WatchChanges streams a signal each time sport events change, without the events themselves.
+	// A first signal is sent once watching. It is only served over gRPC, the gateway uses it to
+	// drop cached responses early.
+	WatchChanges(*WatchChangesRequest, Sports_WatchChangesServer) error
 }
 
 // UnimplementedSportsServer should be embedded to have forward compatible implementations.
@@ -56,6 +304,39 @@ type UnimplementedSportsServer struct {
 func (UnimplementedSportsServer) ListEvents(context.Context, *ListEventsRequest) (*ListEventsReponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
 }
+func (UnimplementedSportsServer) GetEvent(context.Context, *GetEventRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEvent not implemented")
+}
+func (UnimplementedSportsServer) UpdateEventStatus(context.Context, *UpdateEventStatusRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateEventStatus not implemented")
+}
+func (UnimplementedSportsServer) ListSports(context.Context, *ListSportsRequest) (*ListSportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSports not implemented")
+}
+func (UnimplementedSportsServer) ListCompetitions(context.Context, *ListCompetitionsRequest) (*ListCompetitionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCompetitions not implemented")
+}
+func (UnimplementedSportsServer) ListMarkets(context.Context, *ListMarketsRequest) (*ListMarketsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMarkets not implemented")
+}
+func (UnimplementedSportsServer) GetMarket(context.Context, *GetMarketRequest) (*Market, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMarket not implemented")
+}
+func (UnimplementedSportsServer) ListParticipants(context.Context, *ListParticipantsRequest) (*ListParticipantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListParticipants not implemented")
+}
+func (UnimplementedSportsServer) PushScores(Sports_PushScoresServer) error {
+	return status.Errorf(codes.Unimplemented, "method PushScores not implemented")
+}
+func (UnimplementedSportsServer) WatchEvent(*WatchEventRequest, Sports_WatchEventServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvent not implemented")
+}
+func (UnimplementedSportsServer) WatchEvents(*WatchEventsRequest, Sports_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedSportsServer) WatchChanges(*WatchChangesRequest, Sports_WatchChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
 
 // UnsafeSportsServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to SportsServer will
@@ -86,6 +367,221 @@ func _Sports_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sports_GetEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SportsServer).GetEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sports.Sports/GetEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SportsServer).GetEvent(ctx, req.(*GetEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sports_UpdateEventStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEventStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SportsServer).UpdateEventStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sports.Sports/UpdateEventStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SportsServer).UpdateEventStatus(ctx, req.(*UpdateEventStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sports_ListSports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SportsServer).ListSports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sports.Sports/ListSports",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SportsServer).ListSports(ctx, req.(*ListSportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sports_ListCompetitions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCompetitionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SportsServer).ListCompetitions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sports.Sports/ListCompetitions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SportsServer).ListCompetitions(ctx, req.(*ListCompetitionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sports_ListMarkets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMarketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SportsServer).ListMarkets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sports.Sports/ListMarkets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SportsServer).ListMarkets(ctx, req.(*ListMarketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sports_GetMarket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMarketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SportsServer).GetMarket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sports.Sports/GetMarket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SportsServer).GetMarket(ctx, req.(*GetMarketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sports_ListParticipants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListParticipantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SportsServer).ListParticipants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sports.Sports/ListParticipants",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SportsServer).ListParticipants(ctx, req.(*ListParticipantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sports_PushScores_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SportsServer).PushScores(&sportsPushScoresServer{stream})
+}
+
+type Sports_PushScoresServer interface {
+	SendAndClose(*PushScoresResponse) error
+	Recv() (*ScoreUpdate, error)
+	grpc.ServerStream
+}
+
+type sportsPushScoresServer struct {
+	grpc.ServerStream
+}
+
+func (x *sportsPushScoresServer) SendAndClose(m *PushScoresResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sportsPushScoresServer) Recv() (*ScoreUpdate, error) {
+	m := new(ScoreUpdate)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Sports_WatchEvent_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SportsServer).WatchEvent(m, &sportsWatchEventServer{stream})
+}
+
+type Sports_WatchEventServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type sportsWatchEventServer struct {
+	grpc.ServerStream
+}
+
+func (x *sportsWatchEventServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sports_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SportsServer).WatchEvents(m, &sportsWatchEventsServer{stream})
+}
+
+type Sports_WatchEventsServer interface {
+	Send(*EventChange) error
+	grpc.ServerStream
+}
+
+type sportsWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *sportsWatchEventsServer) Send(m *EventChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sports_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SportsServer).WatchChanges(m, &sportsWatchChangesServer{stream})
+}
+
+type Sports_WatchChangesServer interface {
+	Send(*emptypb.Empty) error
+	grpc.ServerStream
+}
+
+type sportsWatchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *sportsWatchChangesServer) Send(m *emptypb.Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Sports_ServiceDesc is the grpc.ServiceDesc for Sports service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -97,7 +593,56 @@ var Sports_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListEvents",
 			Handler:    _Sports_ListEvents_Handler,
 		},
+		{
+			MethodName: "GetEvent",
+			Handler:    _Sports_GetEvent_Handler,
+		},
+		{
+			MethodName: "UpdateEventStatus",
+			Handler:    _Sports_UpdateEventStatus_Handler,
+		},
+		{
+			MethodName: "ListSports",
+			Handler:    _Sports_ListSports_Handler,
+		},
+		{
+			MethodName: "ListCompetitions",
+			Handler:    _Sports_ListCompetitions_Handler,
+		},
+		{
+			MethodName: "ListMarkets",
+			Handler:    _Sports_ListMarkets_Handler,
+		},
+		{
+			MethodName: "GetMarket",
+			Handler:    _Sports_GetMarket_Handler,
+		},
+		{
+			MethodName: "ListParticipants",
+			Handler:    _Sports_ListParticipants_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushScores",
+			Handler:       _Sports_PushScores_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchEvent",
+			Handler:       _Sports_WatchEvent_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Sports_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _Sports_WatchChanges_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "sports/sports.proto",
 }
@@ -0,0 +1,22 @@
+package service
+
+import "git.neds.sh/matty/entain/pkg/auth"
+
+// methodRoles lists the role needed by each write method. Every other method is public.
+var methodRoles = map[string]string{
+	"/sports.Sports/UpdateEventStatus": auth.RoleTrader,
+	"/sports.Sports/PushScores":        auth.RoleTrader,
+}
+
+// NewAuthenticator verifies the bearer tokens write calls carry, the same way the API gateway does,
+// so that the service does not rely on the gateway to have checked them. Tokens are verified
+// against the keys of a JWKS file, or against a single static key. Without either, every token is
+// refused and only public methods can be called.
+func NewAuthenticator(jwksFile, keyFile, issuer, audience string) (*auth.Authorizer, error) {
+	verifier, err := auth.NewVerifier(jwksFile, keyFile, issuer, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewAuthorizer(verifier, methodRoles), nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	rpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sports_grpc_requests_total",
+		Help: "RPCs handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sports_grpc_request_duration_seconds",
+		Help:    "Time taken to handle unary RPCs, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// UnaryMetricsInterceptor counts unary calls by status code and times them.
+func UnaryMetricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	method := methodName(info.FullMethod)
+	rpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	rpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+
+	return resp, err
+}
+
+// StreamMetricsInterceptor counts streams by the status code they ended with. They are not
+// timed, as they last as long as the client watches.
+func StreamMetricsInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+
+	rpcRequests.WithLabelValues(methodName(info.FullMethod), status.Code(err).String()).Inc()
+
+	return err
+}
+
+// methodName strips the service from a full method name, e.g. /sports.Sports/ListEvents.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"io"
+
+	"sports/proto/sports"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PushScores stores each score update as it arrives, then reports how many were stored once the
+// feed closes the stream. An invalid update ends the stream with an error, keeping the updates
+// stored before it.
+func (s *sportsService) PushScores(stream sports.Sports_PushScoresServer) error {
+	ctx := stream.Context()
+
+	var resp sports.PushScoresResponse
+
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&resp)
+		}
+		if err != nil {
+			return err
+		}
+
+		if update.Period < 1 {
+			return status.Errorf(codes.InvalidArgument, "Score for event %d must be for a period from 1", update.EventId)
+		}
+
+		if update.Home < 0 || update.Away < 0 {
+			return status.Errorf(codes.InvalidArgument, "Score for event %d must not be negative", update.EventId)
+		}
+
+		if _, err := s.eventsRepo.Get(ctx, update.EventId); err != nil {
+			return err
+		}
+
+		if update.Timestamp == nil {
+			update.Timestamp = timestamppb.Now()
+		}
+
+		stored, err := s.scoresRepo.Upsert(ctx, update)
+		if err != nil {
+			return err
+		}
+
+		if !stored {
+			resp.Ignored++
+			continue
+		}
+
+		resp.Accepted++
+		s.changes.Notify()
+	}
+}
+
+// attachScores loads the scores of all the events in one query. Events no feed has scored are
+// left without a score.
+func (s *sportsService) attachScores(ctx context.Context, events []*sports.Event) error {
+	byID := make(map[int64]*sports.Event, len(events))
+	ids := make([]int64, 0, len(events))
+
+	for _, event := range events {
+		byID[event.Id] = event
+		ids = append(ids, event.Id)
+	}
+
+	updates, err := s.scoresRepo.List(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// Period scores come ordered by event and period, so the last one seen is the latest period.
+	for _, update := range updates {
+		event := byID[update.EventId]
+		if event.Score == nil {
+			event.Score = &sports.Score{}
+		}
+
+		score := event.Score
+		score.Home += update.Home
+		score.Away += update.Away
+		score.Period = update.Period
+		score.Periods = append(score.Periods, &sports.PeriodScore{Period: update.Period, Home: update.Home, Away: update.Away})
+
+		if score.UpdatedAt == nil || update.Timestamp.AsTime().After(score.UpdatedAt.AsTime()) {
+			score.UpdatedAt = update.Timestamp
+		}
+	}
+
+	return nil
+}
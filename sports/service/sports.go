@@ -4,29 +4,199 @@ import (
 	"sports/db"
 	"sports/proto/sports"
 
+	"git.neds.sh/matty/entain/pkg/notify"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Sports interface {
 	// ListEvents will return a collection of sport events
 	ListEvents(ctx context.Context, in *sports.ListEventsRequest) (*sports.ListEventsReponse, error)
+
+	// GetEvent will return one sport event
+	GetEvent(ctx context.Context, in *sports.GetEventRequest) (*sports.Event, error)
+
+	// UpdateEventStatus will move a sport event to a new status
+	UpdateEventStatus(ctx context.Context, in *sports.UpdateEventStatusRequest) (*sports.Event, error)
+
+	// ListSports will return every sport
+	ListSports(ctx context.Context, in *sports.ListSportsRequest) (*sports.ListSportsResponse, error)
+
+	// ListCompetitions will return a collection of competitions
+	ListCompetitions(ctx context.Context, in *sports.ListCompetitionsRequest) (*sports.ListCompetitionsResponse, error)
+
+	// ListMarkets will return the markets of a sport event
+	ListMarkets(ctx context.Context, in *sports.ListMarketsRequest) (*sports.ListMarketsResponse, error)
+
+	// GetMarket will return one market
+	GetMarket(ctx context.Context, in *sports.GetMarketRequest) (*sports.Market, error)
+
+	// ListParticipants will return the participants of a sport event
+	ListParticipants(ctx context.Context, in *sports.ListParticipantsRequest) (*sports.ListParticipantsResponse, error)
+
+	// PushScores will store the score updates of a live score feed
+	PushScores(stream sports.Sports_PushScoresServer) error
+
+	// WatchEvent will stream a sport event each time it changes
+	WatchEvent(in *sports.WatchEventRequest, stream sports.Sports_WatchEventServer) error
+
+	// WatchEvents will stream changes to the sport events matching a filter
+	WatchEvents(in *sports.WatchEventsRequest, stream sports.Sports_WatchEventsServer) error
+
+	// WatchChanges will stream a signal each time sport events change
+	WatchChanges(in *sports.WatchChangesRequest, stream sports.Sports_WatchChangesServer) error
 }
 
 // sportsService implements the Sports interface.
 type sportsService struct {
-	sportsRepo db.SportsRepo
+	sportsRepo       db.SportsRepo
+	competitionsRepo db.CompetitionsRepo
+	eventsRepo       db.EventsRepo
+	marketsRepo      db.MarketsRepo
+	selectionsRepo   db.SelectionsRepo
+	scoresRepo       db.ScoresRepo
+	participantsRepo db.ParticipantsRepo
+	changes          *notify.Notifier
 }
 
 // NewSportsService instantiates and returns a new sportsService
-func NewSportsService(sportsRepo db.SportsRepo) Sports {
-	return &sportsService{sportsRepo}
+func NewSportsService(sportsRepo db.SportsRepo, competitionsRepo db.CompetitionsRepo, eventsRepo db.EventsRepo, marketsRepo db.MarketsRepo, selectionsRepo db.SelectionsRepo, scoresRepo db.ScoresRepo, participantsRepo db.ParticipantsRepo) Sports {
+	return &sportsService{sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo, notify.New()}
 }
 
 func (s *sportsService) ListEvents(ctx context.Context, in *sports.ListEventsRequest) (*sports.ListEventsReponse, error) {
-	events, err := s.sportsRepo.List(in.Filter)
+	events, err := s.eventsRepo.List(ctx, in.Filter)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.attachScores(ctx, events); err != nil {
+		return nil, err
+	}
+
 	return &sports.ListEventsReponse{Events: events}, nil
 }
+
+func (s *sportsService) GetEvent(ctx context.Context, in *sports.GetEventRequest) (*sports.Event, error) {
+	return s.getEvent(ctx, in.Id)
+}
+
+// getEvent returns one sport event along with its score.
+func (s *sportsService) getEvent(ctx context.Context, id int64) (*sports.Event, error) {
+	event, err := s.eventsRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachScores(ctx, []*sports.Event{event}); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (s *sportsService) UpdateEventStatus(ctx context.Context, in *sports.UpdateEventStatusRequest) (*sports.Event, error) {
+	event, err := s.eventsRepo.Get(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransition(event.Status, in.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Event cannot move from %s to %s", event.Status, in.Status)
+	}
+
+	if _, err := s.eventsRepo.UpdateStatus(ctx, in.Id, event.Status, in.Status); err != nil {
+		return nil, err
+	}
+
+	s.changes.Notify()
+
+	return s.getEvent(ctx, in.Id)
+}
+
+func (s *sportsService) ListSports(ctx context.Context, in *sports.ListSportsRequest) (*sports.ListSportsResponse, error) {
+	list, err := s.sportsRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sports.ListSportsResponse{Sports: list}, nil
+}
+
+func (s *sportsService) ListCompetitions(ctx context.Context, in *sports.ListCompetitionsRequest) (*sports.ListCompetitionsResponse, error) {
+	competitions, err := s.competitionsRepo.List(ctx, in.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sports.ListCompetitionsResponse{Competitions: competitions}, nil
+}
+
+func (s *sportsService) ListMarkets(ctx context.Context, in *sports.ListMarketsRequest) (*sports.ListMarketsResponse, error) {
+	// Unknown events are reported as not found rather than as having no markets.
+	if _, err := s.eventsRepo.Get(ctx, in.EventId); err != nil {
+		return nil, err
+	}
+
+	markets, err := s.marketsRepo.List(ctx, in.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSelections(ctx, markets); err != nil {
+		return nil, err
+	}
+
+	return &sports.ListMarketsResponse{Markets: markets}, nil
+}
+
+func (s *sportsService) GetMarket(ctx context.Context, in *sports.GetMarketRequest) (*sports.Market, error) {
+	market, err := s.marketsRepo.Get(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSelections(ctx, []*sports.Market{market}); err != nil {
+		return nil, err
+	}
+
+	return market, nil
+}
+
+func (s *sportsService) ListParticipants(ctx context.Context, in *sports.ListParticipantsRequest) (*sports.ListParticipantsResponse, error) {
+	// Unknown events are reported as not found rather than as having no participants.
+	if _, err := s.eventsRepo.Get(ctx, in.EventId); err != nil {
+		return nil, err
+	}
+
+	participants, err := s.participantsRepo.List(ctx, in.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sports.ListParticipantsResponse{Participants: participants}, nil
+}
+
+// attachSelections loads the selections of all the markets in one query.
+func (s *sportsService) attachSelections(ctx context.Context, markets []*sports.Market) error {
+	byID := make(map[int64]*sports.Market, len(markets))
+	ids := make([]int64, 0, len(markets))
+
+	for _, market := range markets {
+		byID[market.Id] = market
+		ids = append(ids, market.Id)
+	}
+
+	selections, err := s.selectionsRepo.List(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, selection := range selections {
+		market := byID[selection.MarketId]
+		market.Selections = append(market.Selections, selection)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package service
+
+import "sports/proto/sports"
+
+// statusTransitions lists the statuses an event may move to from each status.
+// FINISHED and CANCELLED are terminal, so they have no entry.
+var statusTransitions = map[sports.EventStatus][]sports.EventStatus{
+	sports.EventStatus_SCHEDULED: {sports.EventStatus_LIVE, sports.EventStatus_SUSPENDED, sports.EventStatus_CANCELLED},
+	sports.EventStatus_LIVE:      {sports.EventStatus_SUSPENDED, sports.EventStatus_FINISHED, sports.EventStatus_CANCELLED},
+	sports.EventStatus_SUSPENDED: {sports.EventStatus_SCHEDULED, sports.EventStatus_LIVE, sports.EventStatus_FINISHED, sports.EventStatus_CANCELLED},
+}
+
+// canTransition reports whether an event may move from one status to another.
+func canTransition(from, to sports.EventStatus) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"sports/proto/sports"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchPollInterval is how often watchers re-read their event, picking up changes made outside
+// this service.
+var watchPollInterval = time.Second
+
+// WatchEvent sends the event with its score, then sends it again each time it changes until the
+// client goes away.
+func (s *sportsService) WatchEvent(in *sports.WatchEventRequest, stream sports.Sports_WatchEventServer) error {
+	ctx := stream.Context()
+
+	changed, unsubscribe := s.changes.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var last *sports.Event
+
+	for {
+		event, err := s.getEvent(ctx, in.Id)
+		if err != nil {
+			return err
+		}
+
+		if last == nil || !proto.Equal(last, event) {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+
+			last = event
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchEvents sends the events matching the filter as CREATED changes, then streams a change each
+// time one of them changes until the client goes away. An event that stops matching the filter,
+// for instance because its status moved on, is reported as DELETED.
+func (s *sportsService) WatchEvents(in *sports.WatchEventsRequest, stream sports.Sports_WatchEventsServer) error {
+	ctx := stream.Context()
+
+	changed, unsubscribe := s.changes.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var snapshot map[int64]*sports.Event
+
+	for {
+		events, err := s.eventsRepo.List(ctx, in.Filter)
+		if err != nil {
+			return err
+		}
+
+		if err := s.attachScores(ctx, events); err != nil {
+			return err
+		}
+
+		next := make(map[int64]*sports.Event, len(events))
+		for _, event := range events {
+			next[event.Id] = event
+		}
+
+		for _, change := range diffEvents(snapshot, next, time.Now()) {
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+
+		snapshot = next
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchChanges sends a signal once it is watching, then again each time this service changes an
+// event, until the client goes away. Unlike WatchEvents it does not read the events, so it costs
+// nothing while nothing changes. Changes made outside this service are not signalled.
+func (s *sportsService) WatchChanges(in *sports.WatchChangesRequest, stream sports.Sports_WatchChangesServer) error {
+	ctx := stream.Context()
+
+	changed, unsubscribe := s.changes.Subscribe()
+	defer unsubscribe()
+
+	for {
+		if err := stream.Send(&emptypb.Empty{}); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// diffEvents compares two snapshots of the watched events, keyed by id, and returns the changes
+// turning the previous one into the next one, ordered by event id.
+func diffEvents(prev, next map[int64]*sports.Event, now time.Time) []*sports.EventChange {
+	var (
+		changes []*sports.EventChange
+		ids     []int64
+	)
+
+	for id := range prev {
+		ids = append(ids, id)
+	}
+	for id := range next {
+		if _, ok := prev[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	ts := timestamppb.New(now)
+
+	for _, id := range ids {
+		before, existed := prev[id]
+		after, exists := next[id]
+
+		switch {
+		case !existed && exists:
+			changes = append(changes, &sports.EventChange{Type: sports.EventChangeType_CREATED, Event: after, Timestamp: ts})
+		case existed && !exists:
+			changes = append(changes, &sports.EventChange{Type: sports.EventChangeType_DELETED, Event: before, Timestamp: ts})
+		case existed && exists && !proto.Equal(before, after):
+			changes = append(changes, &sports.EventChange{Type: sports.EventChangeType_UPDATED, Event: after, Timestamp: ts})
+		}
+	}
+
+	return changes
+}
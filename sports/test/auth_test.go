@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+// testSecret is the HMAC secret the test tokens are signed with.
+var testSecret = []byte("a secret only the issuer and the services know")
+
+// newTestAuthenticator verifies tokens signed with testSecret.
+func newTestAuthenticator(t *testing.T) *auth.Authorizer {
+	t.Helper()
+
+	keyFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(keyFile, testSecret, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := service.NewAuthenticator("", keyFile, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return authenticator
+}
+
+// bearer issues a token for the role signed with key, and sends it as the caller's authorization.
+func bearer(t *testing.T, key []byte, role string) context.Context {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "jane",
+		"role": role,
+		"exp":  time.Now().Add(time.Minute).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuthInterceptors_Roles(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Test Event 1", AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour))}, sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	auth := newTestAuthenticator(t)
+
+	// as sends a token for the role, if any
+	as := func(role string) context.Context {
+		if role == "" {
+			return context.Background()
+		}
+
+		return bearer(t, testSecret, role)
+	}
+
+	updateStatus := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return sportsService.UpdateEventStatus(ctx, req.(*sports.UpdateEventStatusRequest))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/sports.Sports/UpdateEventStatus"}
+	req := &sports.UpdateEventStatusRequest{Id: 1, Status: sports.EventStatus_CANCELLED}
+
+	// Only traders and admins may move an event
+	for role, expected := range map[string]codes.Code{"": codes.Unauthenticated, "public": codes.PermissionDenied, "trader": codes.OK} {
+		_, err := auth.UnaryInterceptor(as(role), req, info, updateStatus)
+		if grpc.Code(err) != expected {
+			t.Errorf("Expected error code %v for %q but got %v", expected, role, grpc.Code(err))
+		}
+	}
+
+	event, err := sportsService.GetEvent(context.Background(), &sports.GetEventRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Status != sports.EventStatus_CANCELLED {
+		t.Errorf("Expected the trader to have cancelled the event but it is %v", event.Status)
+	}
+
+	// Reads are public
+	_, err = auth.UnaryInterceptor(as(""), &sports.GetEventRequest{Id: 1}, &grpc.UnaryServerInfo{FullMethod: "/sports.Sports/GetEvent"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return sportsService.GetEvent(ctx, req.(*sports.GetEventRequest))
+	})
+	if err != nil {
+		t.Errorf("Expected GetEvent to be public but got %v", err)
+	}
+
+	// Score feeds need the trader role too
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/sports.Sports/PushScores", IsClientStream: true}
+	for role, expected := range map[string]codes.Code{"public": codes.PermissionDenied, "admin": codes.OK} {
+		err := auth.StreamInterceptor(nil, &contextStream{ctx: as(role)}, streamInfo, func(interface{}, grpc.ServerStream) error { return nil })
+		if grpc.Code(err) != expected {
+			t.Errorf("Expected error code %v for %q pushing scores but got %v", expected, role, grpc.Code(err))
+		}
+	}
+}
+
+func TestAuthInterceptors_RejectForgedRoles(t *testing.T) {
+	auth := newTestAuthenticator(t)
+
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/sports.Sports/PushScores", IsClientStream: true}
+	push := func(interface{}, grpc.ServerStream) error { return nil }
+
+	// A score feed claiming a role in metadata, as the gateway used to send it, is not trusted
+	forged := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-auth-role", "trader", "x-auth-subject", "feed"))
+	if err := auth.StreamInterceptor(nil, &contextStream{ctx: forged}, streamInfo, push); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected error code %v for a forged role but got %v", codes.Unauthenticated, grpc.Code(err))
+	}
+
+	// Nor is a token it signed itself
+	if err := auth.StreamInterceptor(nil, &contextStream{ctx: bearer(t, []byte("another secret"), "trader")}, streamInfo, push); grpc.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected error code %v for a self-signed token but got %v", codes.Unauthenticated, grpc.Code(err))
+	}
+}
+
+// contextStream is a server stream that only knows its context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"git.neds.sh/matty/entain/pkg/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+func TestWatchDrainer_EndsWatchesOnly(t *testing.T) {
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Test Event 1", AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour))}, sportsDB, t)
+
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	drainer := server.NewWatchDrainer()
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(grpc.StreamInterceptor(drainer.StreamInterceptor))
+	sports.RegisterSportsServer(grpcServer, sportsService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := sports.NewSportsClient(conn)
+
+	watch, err := client.WatchEvents(ctx, &sports.WatchEventsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watch.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := client.PushScores(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := feed.Send(&sports.ScoreUpdate{EventId: 1, Period: 1, Home: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	drainer.Drain()
+
+	// The watch ends straight away
+	if _, err := watch.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected error code %v but got %v", codes.Unavailable, err)
+	}
+
+	// The score feed is left to finish
+	if err := feed.Send(&sports.ScoreUpdate{EventId: 1, Period: 1, Home: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := feed.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("Expected the score feed to finish but got %v", err)
+	}
+	if resp.Accepted != 2 {
+		t.Errorf("Expected 2 updates stored but got %d", resp.Accepted)
+	}
+}
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+// insertTestFilterEvents seeds four events that differ in every filterable field.
+func insertTestFilterEvents(sportsDB *sql.DB, t *testing.T) {
+	for _, event := range []struct {
+		id           int64
+		name         string
+		city         string
+		participants int64
+		start        string
+		visible      bool
+	}{
+		{1, "Storm v Broncos", "Melbourne", 2, "2030-04-05T08:00:00Z", true},
+		{2, "100% Fun Run", "Brisbane", 500, "2030-04-05T06:00:00Z", false},
+		{3, "Broncos v Cowboys", "brisbane", 2, "2030-04-06T09:30:00Z", true},
+		{4, "City to Surf", "Sydney", 1000, "2030-04-04T21:00:00Z", true},
+	} {
+		start, err := time.Parse(time.RFC3339, event.start)
+		if err != nil {
+			t.Fatalf("Failed to parse time: %v", err)
+		}
+
+		InsertNewSportsEvent(&sports.Event{
+			Id:                  event.id,
+			Name:                event.name,
+			CityAddress:         event.city,
+			NumOfParticipants:   event.participants,
+			AdvertisedStartTime: timestamppb.New(start),
+			Visible:             event.visible,
+		}, sportsDB, t)
+	}
+}
+
+func TestListEvents_Filters(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	insertTestFilterEvents(sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	ctx := context.Background()
+	startAfter, err := time.Parse(time.RFC3339, "2030-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	startBefore, err := time.Parse(time.RFC3339, "2030-04-06T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		filter   *sports.ListEventsRequestFilter
+		expected []int64
+	}{
+		{"visible", &sports.ListEventsRequestFilter{Visible: proto.Bool(true)}, []int64{1, 3, 4}},
+		{"hidden", &sports.ListEventsRequestFilter{Visible: proto.Bool(false)}, []int64{2}},
+		{"start window", &sports.ListEventsRequestFilter{StartAfter: timestamppb.New(startAfter), StartBefore: timestamppb.New(startBefore)}, []int64{1, 2}},
+		{"city ignores case", &sports.ListEventsRequestFilter{CityAddress: "BRISBANE"}, []int64{2, 3}},
+		{"participant range", &sports.ListEventsRequestFilter{MinParticipants: proto.Int64(2), MaxParticipants: proto.Int64(500)}, []int64{1, 2, 3}},
+		{"minimum participants", &sports.ListEventsRequestFilter{MinParticipants: proto.Int64(501)}, []int64{4}},
+		{"name search ignores case", &sports.ListEventsRequestFilter{Name: "broncos"}, []int64{1, 3}},
+		{"name search is literal", &sports.ListEventsRequestFilter{Name: "0%"}, []int64{2}},
+		{"name search underscore", &sports.ListEventsRequestFilter{Name: "_"}, nil},
+		{"combined", &sports.ListEventsRequestFilter{Name: "broncos", CityAddress: "brisbane", Visible: proto.Bool(true)}, []int64{3}},
+	} {
+		resp, err := sportsService.ListEvents(ctx, &sports.ListEventsRequest{Filter: tc.filter})
+		if err != nil {
+			t.Fatalf("%s: failed to list events: %v", tc.name, err)
+		}
+
+		var ids []int64
+		for _, event := range resp.Events {
+			ids = append(ids, event.Id)
+		}
+
+		if !equalIds(ids, tc.expected) {
+			t.Errorf("%s: got events %v, expected %v", tc.name, ids, tc.expected)
+		}
+	}
+
+	// Inverted ranges are rejected
+	for _, filter := range []*sports.ListEventsRequestFilter{
+		{StartAfter: timestamppb.New(startBefore), StartBefore: timestamppb.New(startAfter)},
+		{MinParticipants: proto.Int64(10), MaxParticipants: proto.Int64(9)},
+	} {
+		_, err = sportsService.ListEvents(ctx, &sports.ListEventsRequest{Filter: filter})
+		if grpc.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected error code %v for %v but got %v", codes.InvalidArgument, filter, grpc.Code(err))
+		}
+	}
+}
+
+func TestListEvents_Ordering(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	insertTestFilterEvents(sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name     string
+		filter   *sports.ListEventsRequestFilter
+		expected []int64
+	}{
+		{"default", &sports.ListEventsRequestFilter{}, []int64{1, 2, 3, 4}},
+		{"start time by default", &sports.ListEventsRequestFilter{OrderBy: sports.OrderBy_ASC.Enum()}, []int64{4, 2, 1, 3}},
+		{"start time descending", &sports.ListEventsRequestFilter{OrderField: sports.EventOrderField_START_TIME.Enum(), OrderBy: sports.OrderBy_DESC.Enum()}, []int64{3, 1, 2, 4}},
+		{"name ascending by default", &sports.ListEventsRequestFilter{OrderField: sports.EventOrderField_NAME.Enum()}, []int64{2, 3, 4, 1}},
+		{"name descending", &sports.ListEventsRequestFilter{OrderField: sports.EventOrderField_NAME.Enum(), OrderBy: sports.OrderBy_DESC.Enum()}, []int64{1, 4, 3, 2}},
+		{"participants breaks ties by id", &sports.ListEventsRequestFilter{OrderField: sports.EventOrderField_NUM_OF_PARTICIPANTS.Enum()}, []int64{1, 3, 2, 4}},
+		{"participants descending", &sports.ListEventsRequestFilter{OrderField: sports.EventOrderField_NUM_OF_PARTICIPANTS.Enum(), OrderBy: sports.OrderBy_DESC.Enum()}, []int64{4, 2, 3, 1}},
+	} {
+		resp, err := sportsService.ListEvents(ctx, &sports.ListEventsRequest{Filter: tc.filter})
+		if err != nil {
+			t.Fatalf("%s: failed to list events: %v", tc.name, err)
+		}
+
+		var ids []int64
+		for _, event := range resp.Events {
+			ids = append(ids, event.Id)
+		}
+
+		if !equalIds(ids, tc.expected) {
+			t.Errorf("%s: got events %v, expected %v", tc.name, ids, tc.expected)
+		}
+	}
+}
+
+func equalIds(got, expected []int64) bool {
+	if len(got) != len(expected) {
+		return false
+	}
+
+	for i := range got {
+		if got[i] != expected[i] {
+			return false
+		}
+	}
+
+	return true
+}
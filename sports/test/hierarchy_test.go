@@ -0,0 +1,241 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+func InsertNewSport(sport *sports.Sport, r *sql.DB, t *testing.T) {
+	_, err := r.Exec(getSportsEventQueriesForTest()[insertSport], &sport.Id, &sport.Name)
+	if err != nil {
+		t.Fatalf("Failed to insert sport record: %v", err)
+	}
+}
+
+func InsertNewCompetition(competition *sports.Competition, r *sql.DB, t *testing.T) {
+	_, err := r.Exec(getSportsEventQueriesForTest()[insertCompetition], &competition.Id, &competition.SportId, &competition.Name)
+	if err != nil {
+		t.Fatalf("Failed to insert competition record: %v", err)
+	}
+}
+
+// insertTestHierarchy seeds two sports, three competitions and an event in each competition.
+func insertTestHierarchy(sportsDB *sql.DB, t *testing.T) time.Time {
+	timeTest, err := time.Parse(time.RFC3339, "2004-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatalf("Failed to parse time: %v", err)
+	}
+
+	InsertNewSport(&sports.Sport{Id: 1, Name: "Tennis"}, sportsDB, t)
+	InsertNewSport(&sports.Sport{Id: 2, Name: "Basketball"}, sportsDB, t)
+
+	InsertNewCompetition(&sports.Competition{Id: 1, SportId: 1, Name: "Wimbledon"}, sportsDB, t)
+	InsertNewCompetition(&sports.Competition{Id: 2, SportId: 2, Name: "NBL"}, sportsDB, t)
+	InsertNewCompetition(&sports.Competition{Id: 3, SportId: 1, Name: "Australian Open"}, sportsDB, t)
+
+	for id, competitionID := range map[int64]int64{1: 1, 2: 2, 3: 3} {
+		InsertNewSportsEvent(&sports.Event{
+			Id:                  id,
+			CompetitionId:       competitionID,
+			Name:                "Test Event",
+			CityAddress:         "Melbourne",
+			NumOfParticipants:   2,
+			AdvertisedStartTime: timestamppb.New(timeTest),
+		}, sportsDB, t)
+	}
+
+	return timeTest
+}
+
+func TestListSports_Default(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	insertTestHierarchy(sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	resp, err := sportsService.ListSports(context.Background(), &sports.ListSportsRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list sports: %v", err)
+	}
+
+	// Sports are ordered by name
+	expectedSports := []*sports.Sport{
+		{Id: 2, Name: "Basketball"},
+		{Id: 1, Name: "Tennis"},
+	}
+	if !reflect.DeepEqual(resp.Sports, expectedSports) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Sports, expectedSports)
+	}
+}
+
+func TestListCompetitions_WithSportFilter(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	insertTestHierarchy(sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	ctx := context.Background()
+
+	// Without a filter every competition is returned, grouped by sport and ordered by name
+	resp, err := sportsService.ListCompetitions(ctx, &sports.ListCompetitionsRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list competitions: %v", err)
+	}
+
+	expectedCompetitions := []*sports.Competition{
+		{Id: 3, SportId: 1, Name: "Australian Open"},
+		{Id: 1, SportId: 1, Name: "Wimbledon"},
+		{Id: 2, SportId: 2, Name: "NBL"},
+	}
+	if !reflect.DeepEqual(resp.Competitions, expectedCompetitions) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Competitions, expectedCompetitions)
+	}
+
+	resp, err = sportsService.ListCompetitions(ctx, &sports.ListCompetitionsRequest{
+		Filter: &sports.ListCompetitionsRequestFilter{SportIds: []int64{2}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to list competitions: %v", err)
+	}
+
+	expectedCompetitions = []*sports.Competition{
+		{Id: 2, SportId: 2, Name: "NBL"},
+	}
+	if !reflect.DeepEqual(resp.Competitions, expectedCompetitions) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Competitions, expectedCompetitions)
+	}
+}
+
+func TestListEvents_WithSportAndCompetitionFilter(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	timeTest := insertTestHierarchy(sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	ctx := context.Background()
+
+	event := func(id, sportID, competitionID int64) *sports.Event {
+		return &sports.Event{
+			Id:                  id,
+			SportId:             sportID,
+			CompetitionId:       competitionID,
+			Name:                "Test Event",
+			CityAddress:         "Melbourne",
+			NumOfParticipants:   2,
+			AdvertisedStartTime: timestamppb.New(timeTest),
+		}
+	}
+
+	for _, tc := range []struct {
+		filter   *sports.ListEventsRequestFilter
+		expected []*sports.Event
+	}{
+		{&sports.ListEventsRequestFilter{SportIds: []int64{1}}, []*sports.Event{event(1, 1, 1), event(3, 1, 3)}},
+		{&sports.ListEventsRequestFilter{CompetitionIds: []int64{2, 3}}, []*sports.Event{event(2, 2, 2), event(3, 1, 3)}},
+		{&sports.ListEventsRequestFilter{SportIds: []int64{1}, CompetitionIds: []int64{2}}, nil},
+	} {
+		resp, err := sportsService.ListEvents(ctx, &sports.ListEventsRequest{Filter: tc.filter})
+		if err != nil {
+			t.Fatalf("Failed to list events: %v", err)
+		}
+
+		if !reflect.DeepEqual(resp.Events, tc.expected) {
+			t.Errorf("Response for %v did not match expected value. Got %v, expected %v", tc.filter, resp.Events, tc.expected)
+		}
+	}
+}
+
+func TestSportsRepo_InitKeepsLegacyEvents(t *testing.T) {
+	// A database created before sports were modelled, with its events in the sports table
+	sportsDB, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer sportsDB.Close()
+
+	_, err = sportsDB.Exec(`CREATE TABLE sports (id INTEGER PRIMARY KEY, name TEXT, city_address TEXT, num_of_participants INTEGER, advertised_start_time DATETIME)`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+	_, err = sportsDB.Exec(`INSERT INTO sports VALUES (1, 'Legacy Event', 'Hobart', 12, '2004-04-05T00:00:00Z')`)
+	if err != nil {
+		t.Fatalf("Failed to insert legacy event: %v", err)
+	}
+
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	for _, repo := range []interface{ Init() error }{sportsRepo, db.NewCompetitionsRepo(sportsDB), eventsRepo, db.NewParticipantsRepo(sportsDB)} {
+		if err := repo.Init(); err != nil {
+			t.Fatalf("Failed to init repo: %v", err)
+		}
+	}
+
+	// The legacy event is kept, outside any competition
+	event, err := eventsRepo.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+	if event.Name != "Legacy Event" || event.CityAddress != "Hobart" || event.CompetitionId != 0 || event.SportId != 0 {
+		t.Errorf("Expected the legacy event outside any competition, got %v", event)
+	}
+
+	// And the sports table now holds sports
+	list, err := sportsRepo.List(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list sports: %v", err)
+	}
+	if len(list) == 0 || list[0].Name == "Legacy Event" {
+		t.Errorf("Expected the seeded sports, got %v", list)
+	}
+}
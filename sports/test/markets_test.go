@@ -0,0 +1,254 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+func InsertNewMarket(market *sports.Market, r *sql.DB, t *testing.T) {
+	_, err := r.Exec(getSportsEventQueriesForTest()[insertMarket], &market.Id, &market.EventId, &market.Name, &market.Type, &market.Line, &market.InPlay, &market.Status)
+	if err != nil {
+		t.Fatalf("Failed to insert market record: %v", err)
+	}
+}
+
+func InsertNewSelection(selection *sports.Selection, r *sql.DB, t *testing.T) {
+	_, err := r.Exec(getSportsEventQueriesForTest()[insertSelection], &selection.Id, &selection.MarketId, &selection.Name, &selection.Price, &selection.Status)
+	if err != nil {
+		t.Fatalf("Failed to insert selection record: %v", err)
+	}
+}
+
+func TestListMarkets_StatusFollowsEvent(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	// Event 1 is LIVE, events 2 and 3 are yet to start
+	timeTest1, err := time.Parse(time.RFC3339, "2004-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeTest2, err := time.Parse(time.RFC3339, "4452-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Test Event 1", AdvertisedStartTime: timestamppb.New(timeTest1)}, sportsDB, t)
+	InsertNewSportsEvent(&sports.Event{Id: 2, Name: "Test Event 2", AdvertisedStartTime: timestamppb.New(timeTest2)}, sportsDB, t)
+	InsertNewSportsEvent(&sports.Event{Id: 3, Name: "Test Event 3", AdvertisedStartTime: timestamppb.New(timeTest2)}, sportsDB, t)
+
+	InsertNewMarket(&sports.Market{Id: 1, EventId: 1, Name: "Head to Head", Type: sports.MarketType_HEAD_TO_HEAD, InPlay: true}, sportsDB, t)
+	InsertNewMarket(&sports.Market{Id: 2, EventId: 1, Name: "Line", Type: sports.MarketType_LINE, Line: -3.5}, sportsDB, t)
+	InsertNewMarket(&sports.Market{Id: 3, EventId: 1, Name: "Total Points", Type: sports.MarketType_TOTAL, Line: 150.5, Status: sports.MarketStatus_MARKET_CLOSED}, sportsDB, t)
+	InsertNewMarket(&sports.Market{Id: 4, EventId: 2, Name: "Head to Head", Type: sports.MarketType_HEAD_TO_HEAD}, sportsDB, t)
+
+	InsertNewSelection(&sports.Selection{Id: 1, MarketId: 1, Name: "Home", Price: 1.9}, sportsDB, t)
+	InsertNewSelection(&sports.Selection{Id: 2, MarketId: 1, Name: "Away", Price: 2.1, Status: sports.SelectionStatus_SELECTION_SUSPENDED}, sportsDB, t)
+	InsertNewSelection(&sports.Selection{Id: 3, MarketId: 4, Name: "Home", Price: 1.5}, sportsDB, t)
+	InsertNewSelection(&sports.Selection{Id: 4, MarketId: 4, Name: "Away", Price: 2.75}, sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	ctx := context.Background()
+
+	_, err = sportsService.UpdateEventStatus(ctx, &sports.UpdateEventStatusRequest{Id: 1, Status: sports.EventStatus_LIVE})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// While event 1 is LIVE only its in-play market stays open, and a stored status wins
+	resp, err := sportsService.ListMarkets(ctx, &sports.ListMarketsRequest{EventId: 1})
+	if err != nil {
+		t.Fatalf("Failed to list markets: %v", err)
+	}
+
+	expectedMarkets := []*sports.Market{
+		{Id: 1, EventId: 1, Name: "Head to Head", Type: sports.MarketType_HEAD_TO_HEAD, InPlay: true, Status: sports.MarketStatus_MARKET_OPEN, Selections: []*sports.Selection{
+			{Id: 1, MarketId: 1, Name: "Home", Price: 1.9},
+			{Id: 2, MarketId: 1, Name: "Away", Price: 2.1, Status: sports.SelectionStatus_SELECTION_SUSPENDED},
+		}},
+		{Id: 2, EventId: 1, Name: "Line", Type: sports.MarketType_LINE, Line: -3.5, Status: sports.MarketStatus_MARKET_SUSPENDED},
+		{Id: 3, EventId: 1, Name: "Total Points", Type: sports.MarketType_TOTAL, Line: 150.5, Status: sports.MarketStatus_MARKET_CLOSED},
+	}
+	if !reflect.DeepEqual(resp.Markets, expectedMarkets) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Markets, expectedMarkets)
+	}
+
+	// An event without markets has none listed
+	resp, err = sportsService.ListMarkets(ctx, &sports.ListMarketsRequest{EventId: 3})
+	if err != nil {
+		t.Fatalf("Failed to list markets: %v", err)
+	}
+	if len(resp.Markets) != 0 {
+		t.Errorf("Expected no markets but got %v", resp.Markets)
+	}
+
+	// Markets that are not in-play are suspended as soon as their event goes LIVE
+	market, err := sportsService.GetMarket(ctx, &sports.GetMarketRequest{Id: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if market.Status != sports.MarketStatus_MARKET_OPEN || len(market.Selections) != 2 {
+		t.Errorf("Expected an open market with 2 selections but got %v", market)
+	}
+
+	_, err = sportsService.UpdateEventStatus(ctx, &sports.UpdateEventStatusRequest{Id: 2, Status: sports.EventStatus_LIVE})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	market, err = sportsService.GetMarket(ctx, &sports.GetMarketRequest{Id: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if market.Status != sports.MarketStatus_MARKET_SUSPENDED {
+		t.Errorf("Expected market 4 to be %v but got %v", sports.MarketStatus_MARKET_SUSPENDED, market.Status)
+	}
+
+	// Unknown events and markets are not found
+	_, err = sportsService.ListMarkets(ctx, &sports.ListMarketsRequest{EventId: 99})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+
+	_, err = sportsService.GetMarket(ctx, &sports.GetMarketRequest{Id: 99})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
+
+func TestMarketsRepo_StatusFollowsEventStatus(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	past, err := time.Parse(time.RFC3339, "2004-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+
+	ctx := context.Background()
+
+	// Each event has an in-play market, a pre-match one, one stored as suspended and one stored as
+	// settled. The in-play market has an unresulted and a won selection, the settled one an
+	// unresulted selection.
+	tests := []struct {
+		status     sports.EventStatus
+		markets    []sports.MarketStatus
+		selections []sports.SelectionStatus
+	}{
+		{
+			sports.EventStatus_SCHEDULED,
+			[]sports.MarketStatus{sports.MarketStatus_MARKET_OPEN, sports.MarketStatus_MARKET_OPEN, sports.MarketStatus_MARKET_SUSPENDED, sports.MarketStatus_MARKET_SETTLED},
+			[]sports.SelectionStatus{sports.SelectionStatus_SELECTION_OPEN, sports.SelectionStatus_SELECTION_WON, sports.SelectionStatus_SELECTION_SUSPENDED},
+		},
+		{
+			sports.EventStatus_LIVE,
+			[]sports.MarketStatus{sports.MarketStatus_MARKET_OPEN, sports.MarketStatus_MARKET_SUSPENDED, sports.MarketStatus_MARKET_SUSPENDED, sports.MarketStatus_MARKET_SETTLED},
+			[]sports.SelectionStatus{sports.SelectionStatus_SELECTION_OPEN, sports.SelectionStatus_SELECTION_WON, sports.SelectionStatus_SELECTION_SUSPENDED},
+		},
+		{
+			sports.EventStatus_SUSPENDED,
+			[]sports.MarketStatus{sports.MarketStatus_MARKET_SUSPENDED, sports.MarketStatus_MARKET_SUSPENDED, sports.MarketStatus_MARKET_SUSPENDED, sports.MarketStatus_MARKET_SETTLED},
+			[]sports.SelectionStatus{sports.SelectionStatus_SELECTION_SUSPENDED, sports.SelectionStatus_SELECTION_WON, sports.SelectionStatus_SELECTION_SUSPENDED},
+		},
+		{
+			sports.EventStatus_FINISHED,
+			[]sports.MarketStatus{sports.MarketStatus_MARKET_CLOSED, sports.MarketStatus_MARKET_CLOSED, sports.MarketStatus_MARKET_CLOSED, sports.MarketStatus_MARKET_SETTLED},
+			[]sports.SelectionStatus{sports.SelectionStatus_SELECTION_SUSPENDED, sports.SelectionStatus_SELECTION_WON, sports.SelectionStatus_SELECTION_SUSPENDED},
+		},
+		{
+			sports.EventStatus_CANCELLED,
+			[]sports.MarketStatus{sports.MarketStatus_MARKET_CLOSED, sports.MarketStatus_MARKET_CLOSED, sports.MarketStatus_MARKET_CLOSED, sports.MarketStatus_MARKET_SETTLED},
+			[]sports.SelectionStatus{sports.SelectionStatus_SELECTION_SUSPENDED, sports.SelectionStatus_SELECTION_WON, sports.SelectionStatus_SELECTION_SUSPENDED},
+		},
+	}
+
+	for i, test := range tests {
+		eventID := int64(i + 1)
+
+		// Every event has started, but only the stored status counts
+		InsertNewSportsEvent(&sports.Event{Id: eventID, Name: "Test Event", AdvertisedStartTime: timestamppb.New(past)}, sportsDB, t)
+		if test.status != sports.EventStatus_SCHEDULED {
+			if _, err := eventsRepo.UpdateStatus(ctx, eventID, sports.EventStatus_SCHEDULED, test.status); err != nil {
+				t.Fatalf("Failed to move event %d to %v: %v", eventID, test.status, err)
+			}
+		}
+
+		marketID := eventID * 10
+		InsertNewMarket(&sports.Market{Id: marketID + 1, EventId: eventID, Name: "Head to Head", InPlay: true}, sportsDB, t)
+		InsertNewMarket(&sports.Market{Id: marketID + 2, EventId: eventID, Name: "Line", Type: sports.MarketType_LINE}, sportsDB, t)
+		InsertNewMarket(&sports.Market{Id: marketID + 3, EventId: eventID, Name: "Total Points", Type: sports.MarketType_TOTAL, Status: sports.MarketStatus_MARKET_SUSPENDED}, sportsDB, t)
+		InsertNewMarket(&sports.Market{Id: marketID + 4, EventId: eventID, Name: "First Scorer", Status: sports.MarketStatus_MARKET_SETTLED}, sportsDB, t)
+
+		selectionID := eventID * 10
+		InsertNewSelection(&sports.Selection{Id: selectionID + 1, MarketId: marketID + 1, Name: "Home", Price: 1.9}, sportsDB, t)
+		InsertNewSelection(&sports.Selection{Id: selectionID + 2, MarketId: marketID + 1, Name: "Away", Price: 2.1, Status: sports.SelectionStatus_SELECTION_WON}, sportsDB, t)
+		InsertNewSelection(&sports.Selection{Id: selectionID + 3, MarketId: marketID + 4, Name: "Home", Price: 3.5}, sportsDB, t)
+
+		markets, err := marketsRepo.List(ctx, eventID)
+		if err != nil {
+			t.Fatalf("Failed to list markets: %v", err)
+		}
+
+		var marketStatuses []sports.MarketStatus
+		var marketIDs []int64
+		for _, market := range markets {
+			marketStatuses = append(marketStatuses, market.Status)
+			marketIDs = append(marketIDs, market.Id)
+		}
+		if !reflect.DeepEqual(marketStatuses, test.markets) {
+			t.Errorf("Expected markets of a %v event to be %v but got %v", test.status, test.markets, marketStatuses)
+		}
+
+		// Getting a market resolves its status the same way
+		market, err := marketsRepo.Get(ctx, marketID+2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if market.Status != test.markets[1] {
+			t.Errorf("Expected market %d of a %v event to be %v but got %v", market.Id, test.status, test.markets[1], market.Status)
+		}
+
+		selections, err := selectionsRepo.List(ctx, marketIDs)
+		if err != nil {
+			t.Fatalf("Failed to list selections: %v", err)
+		}
+
+		var selectionStatuses []sports.SelectionStatus
+		for _, selection := range selections {
+			selectionStatuses = append(selectionStatuses, selection.Status)
+		}
+		if !reflect.DeepEqual(selectionStatuses, test.selections) {
+			t.Errorf("Expected selections of a %v event to be %v but got %v", test.status, test.selections, selectionStatuses)
+		}
+	}
+}
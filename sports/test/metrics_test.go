@@ -0,0 +1,68 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sports/service"
+)
+
+// requestCount reads how many calls to a method ended with a status code, 0 if none did.
+func requestCount(t *testing.T, method string, code codes.Code) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "sports_grpc_requests_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["method"] == method && labels["code"] == code.String() {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestMetricsInterceptors_CountByCode(t *testing.T) {
+	before := requestCount(t, "GetEvent", codes.NotFound)
+
+	if _, err := service.UnaryMetricsInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/sports.Sports/GetEvent"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "event not found")
+	}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected the handler's error but got %v", err)
+	}
+
+	if got := requestCount(t, "GetEvent", codes.NotFound) - before; got != 1 {
+		t.Errorf("Expected 1 NotFound call to GetEvent counted but got %v", got)
+	}
+
+	// Streams are counted once they end, such as a watch ended by a shutdown
+	before = requestCount(t, "WatchEvent", codes.Unavailable)
+
+	if err := service.StreamMetricsInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/sports.Sports/WatchEvent"}, func(srv interface{}, ss grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "server is shutting down")
+	}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Expected the handler's error but got %v", err)
+	}
+
+	if got := requestCount(t, "WatchEvent", codes.Unavailable) - before; got != 1 {
+		t.Errorf("Expected 1 Unavailable stream of WatchEvent counted but got %v", got)
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+func InsertNewParticipant(participant *sports.Participant, r *sql.DB, t *testing.T) {
+	_, err := r.Exec(getSportsEventQueriesForTest()[insertParticipant], &participant.Id, &participant.EventId, &participant.Name, &participant.Type, &participant.Role, &participant.Country)
+	if err != nil {
+		t.Fatalf("Failed to insert participant record: %v", err)
+	}
+}
+
+func TestListParticipants_CountIsDerived(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	timeTest, err := time.Parse(time.RFC3339, "2030-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Nadal v Federer", AdvertisedStartTime: timestamppb.New(timeTest)}, sportsDB, t)
+	InsertNewSportsEvent(&sports.Event{Id: 2, Name: "Storm v Broncos", AdvertisedStartTime: timestamppb.New(timeTest)}, sportsDB, t)
+
+	// The away side is inserted first, but the home side is listed first
+	InsertNewParticipant(&sports.Participant{Id: 1, EventId: 1, Name: "Federer", Type: sports.ParticipantType_INDIVIDUAL, Role: sports.ParticipantRole_AWAY, Country: "Switzerland"}, sportsDB, t)
+	InsertNewParticipant(&sports.Participant{Id: 2, EventId: 1, Name: "Nadal", Type: sports.ParticipantType_INDIVIDUAL, Role: sports.ParticipantRole_HOME, Country: "Spain"}, sportsDB, t)
+	InsertNewParticipant(&sports.Participant{Id: 3, EventId: 2, Name: "Storm", Type: sports.ParticipantType_TEAM, Role: sports.ParticipantRole_HOME, Country: "Australia"}, sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	ctx := context.Background()
+
+	resp, err := sportsService.ListParticipants(ctx, &sports.ListParticipantsRequest{EventId: 1})
+	if err != nil {
+		t.Fatalf("Failed to list participants: %v", err)
+	}
+
+	expectedParticipants := []*sports.Participant{
+		{Id: 2, EventId: 1, Name: "Nadal", Type: sports.ParticipantType_INDIVIDUAL, Role: sports.ParticipantRole_HOME, Country: "Spain"},
+		{Id: 1, EventId: 1, Name: "Federer", Type: sports.ParticipantType_INDIVIDUAL, Role: sports.ParticipantRole_AWAY, Country: "Switzerland"},
+	}
+	if !reflect.DeepEqual(resp.Participants, expectedParticipants) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Participants, expectedParticipants)
+	}
+
+	// The number of participants follows the linked participants
+	for id, expected := range map[int64]int64{1: 2, 2: 1} {
+		event, err := sportsService.GetEvent(ctx, &sports.GetEventRequest{Id: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.NumOfParticipants != expected {
+			t.Errorf("Expected event %d to have %d participants but got %d", id, expected, event.NumOfParticipants)
+		}
+	}
+
+	InsertNewParticipant(&sports.Participant{Id: 4, EventId: 2, Name: "Broncos", Type: sports.ParticipantType_TEAM, Role: sports.ParticipantRole_AWAY, Country: "Australia"}, sportsDB, t)
+
+	events, err := sportsService.ListEvents(ctx, &sports.ListEventsRequest{Filter: &sports.ListEventsRequestFilter{MinParticipants: proto.Int64(2)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Events) != 2 {
+		t.Errorf("Expected both events to have 2 participants but got %v", events.Events)
+	}
+
+	// Unknown events are not found
+	_, err = sportsService.ListParticipants(ctx, &sports.ListParticipantsRequest{EventId: 99})
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
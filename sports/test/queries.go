@@ -3,21 +3,90 @@ package test
 const (
 	clearAllDataSportsEvents = "clearSportsEvents"
 	insertSportEvent         = "insertSportEvent"
+	insertSport              = "insertSport"
+	insertCompetition        = "insertCompetition"
+	insertMarket             = "insertMarket"
+	insertSelection          = "insertSelection"
+	insertParticipant        = "insertParticipant"
+	insertEventParticipants  = "insertEventParticipants"
 )
 
 func getSportsEventQueriesForTest() map[string]string {
 	return map[string]string{
-		clearAllDataSportsEvents: `DELETE FROM sports`,
+		clearAllDataSportsEvents: `DELETE FROM events`,
 		insertSportEvent: `
 		INSERT OR IGNORE INTO
-		sports
+		events
 		(id,
+		competition_id,
 		name,
 		city_address, 
-		num_of_participants, 
-		advertised_start_time) 
+		advertised_start_time, 
+		visible) 
 		VALUES 
+		(?,?,?,?,?,?)
+		`,
+		insertSport: `
+		INSERT OR IGNORE INTO
+		sports
+		(id,
+		name)
+		VALUES
+		(?,?)
+		`,
+		insertCompetition: `
+		INSERT OR IGNORE INTO
+		competitions
+		(id,
+		sport_id,
+		name)
+		VALUES
+		(?,?,?)
+		`,
+		insertMarket: `
+		INSERT OR IGNORE INTO
+		markets
+		(id,
+		event_id,
+		name,
+		type,
+		line,
+		in_play,
+		status)
+		VALUES
+		(?,?,?,?,?,?,?)
+		`,
+		insertSelection: `
+		INSERT OR IGNORE INTO
+		selections
+		(id,
+		market_id,
+		name,
+		price,
+		status)
+		VALUES
 		(?,?,?,?,?)
 		`,
+		insertParticipant: `
+		INSERT OR IGNORE INTO
+		participants
+		(id,
+		event_id,
+		name,
+		type,
+		role,
+		country)
+		VALUES
+		(?,?,?,?,?,?)
+		`,
+		// Inserts the given number of participants, named Participant 1 to n, in one statement.
+		insertEventParticipants: `
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < ?)
+		INSERT INTO
+		participants
+		(event_id,
+		name)
+		SELECT ?, 'Participant ' || i FROM n
+		`,
 	}
 }
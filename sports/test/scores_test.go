@@ -0,0 +1,178 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+func TestPushScores_WatchEvent(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	timeTest, err := time.Parse(time.RFC3339, "2004-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Test Event 1", AdvertisedStartTime: timestamppb.New(timeTest)}, sportsDB, t)
+	InsertNewSportsEvent(&sports.Event{Id: 2, Name: "Test Event 2", AdvertisedStartTime: timestamppb.New(timeTest)}, sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	// Serve the sports service over an in-memory connection
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	sports.RegisterSportsServer(grpcServer, sportsService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := sports.NewSportsClient(conn)
+
+	push := func(updates ...*sports.ScoreUpdate) (*sports.PushScoresResponse, error) {
+		t.Helper()
+
+		stream, err := client.PushScores(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, update := range updates {
+			if err := stream.Send(update); err != nil {
+				break
+			}
+		}
+
+		return stream.CloseAndRecv()
+	}
+
+	t1 := timestamppb.New(timeTest.Add(10 * time.Minute))
+	t2 := timestamppb.New(timeTest.Add(50 * time.Minute))
+
+	// An update older than the stored score of its period is ignored
+	resp, err := push(
+		&sports.ScoreUpdate{EventId: 1, Period: 1, Home: 3, Away: 1, Timestamp: t1},
+		&sports.ScoreUpdate{EventId: 1, Period: 2, Home: 0, Away: 2, Timestamp: t2},
+		&sports.ScoreUpdate{EventId: 1, Period: 1, Home: 9, Away: 9, Timestamp: timestamppb.New(timeTest)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Accepted != 2 || resp.Ignored != 1 {
+		t.Errorf("Expected 2 updates accepted and 1 ignored but got %v", resp)
+	}
+
+	event, err := sportsService.GetEvent(ctx, &sports.GetEventRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedScore := &sports.Score{
+		Home:   3,
+		Away:   3,
+		Period: 2,
+		Periods: []*sports.PeriodScore{
+			{Period: 1, Home: 3, Away: 1},
+			{Period: 2, Home: 0, Away: 2},
+		},
+		UpdatedAt: t2,
+	}
+	if !proto.Equal(event.Score, expectedScore) {
+		t.Errorf("Expected score %v but got %v", expectedScore, event.Score)
+	}
+
+	// Invalid updates and unknown events end the stream
+	for _, tc := range []struct {
+		update *sports.ScoreUpdate
+		code   codes.Code
+	}{
+		{&sports.ScoreUpdate{EventId: 1, Period: 0, Home: 1}, codes.InvalidArgument},
+		{&sports.ScoreUpdate{EventId: 1, Period: 3, Home: -1}, codes.InvalidArgument},
+		{&sports.ScoreUpdate{EventId: 99, Period: 1, Home: 1}, codes.NotFound},
+	} {
+		_, err = push(tc.update)
+		if grpc.Code(err) != tc.code {
+			t.Errorf("Expected error code %v for %v but got %v", tc.code, tc.update, grpc.Code(err))
+		}
+	}
+
+	_, err = sportsService.UpdateEventStatus(ctx, &sports.UpdateEventStatusRequest{Id: 2, Status: sports.EventStatus_LIVE})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Watching sends the event, then each change to it
+	stream, err := client.WatchEvent(ctx, &sports.WatchEventRequest{Id: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectEvent := func(check func(*sports.Event) bool) {
+		t.Helper()
+
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Expected an event but got %v", err)
+		}
+
+		if !check(event) {
+			t.Errorf("Unexpected event %v", event)
+		}
+	}
+
+	expectEvent(func(e *sports.Event) bool { return e.Id == 2 && e.Status == sports.EventStatus_LIVE && e.Score == nil })
+
+	_, err = push(&sports.ScoreUpdate{EventId: 2, Period: 1, Home: 1, Away: 0, Timestamp: t1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(func(e *sports.Event) bool { return e.Score.GetHome() == 1 && e.Score.GetAway() == 0 })
+
+	_, err = sportsService.UpdateEventStatus(ctx, &sports.UpdateEventStatusRequest{Id: 2, Status: sports.EventStatus_FINISHED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(func(e *sports.Event) bool { return e.Status == sports.EventStatus_FINISHED && e.Score.GetHome() == 1 })
+
+	// Unknown events cannot be watched
+	stream, err = client.WatchEvent(ctx, &sports.WatchEventRequest{Id: 99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
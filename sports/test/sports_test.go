@@ -10,6 +10,9 @@ import (
 
 	"github.com/golang/protobuf/ptypes"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"sports/db"
 	"sports/proto/sports"
@@ -28,8 +31,46 @@ func NewTestSportDB() (*sql.DB, error) {
 		return nil, err
 	}
 
+	// Recreate the tables so they always match the current schema and start empty.
+	for _, table := range []string{"sports", "competitions", "events", "participants", "markets", "selections", "scores"} {
+		_, err = sportsDB.Exec(`DROP TABLE IF EXISTS ` + table)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize the test database by running SQL scripts.
-	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS sports (id INTEGER PRIMARY KEY, name TEXT, city_address TEXT, num_of_participants INTEGER, advertised_start_time DATETIME)`)
+	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS sports (id INTEGER PRIMARY KEY, name TEXT)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS competitions (id INTEGER PRIMARY KEY, sport_id INTEGER, name TEXT)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS events (id INTEGER PRIMARY KEY, competition_id INTEGER, name TEXT, city_address TEXT, advertised_start_time DATETIME, visible INTEGER NOT NULL DEFAULT 0, status INTEGER)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS participants (id INTEGER PRIMARY KEY, event_id INTEGER, name TEXT, type INTEGER, role INTEGER, country TEXT)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS markets (id INTEGER PRIMARY KEY, event_id INTEGER, name TEXT, type INTEGER, line REAL, in_play INTEGER NOT NULL DEFAULT 0, status INTEGER)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS selections (id INTEGER PRIMARY KEY, market_id INTEGER, name TEXT, price REAL, status INTEGER)`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = sportsDB.Exec(`CREATE TABLE IF NOT EXISTS scores (event_id INTEGER, period INTEGER, home INTEGER, away INTEGER, updated_at DATETIME, PRIMARY KEY (event_id, period))`)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +78,8 @@ func NewTestSportDB() (*sql.DB, error) {
 	return sportsDB, nil
 }
 
+// InsertNewSportsEvent inserts an event along with NumOfParticipants anonymous participants, as
+// the count is derived from the participants linked to the event.
 func InsertNewSportsEvent(sportsEvent *sports.Event, r *sql.DB, t *testing.T) {
 	ts, err := ptypes.Timestamp(sportsEvent.AdvertisedStartTime)
 	if err != nil {
@@ -44,15 +87,23 @@ func InsertNewSportsEvent(sportsEvent *sports.Event, r *sql.DB, t *testing.T) {
 	}
 	_, err = r.Exec(getSportsEventQueriesForTest()[insertSportEvent],
 		&sportsEvent.Id,
+		&sportsEvent.CompetitionId,
 		&sportsEvent.Name,
 		&sportsEvent.CityAddress,
-		&sportsEvent.NumOfParticipants,
 		ts,
+		&sportsEvent.Visible,
 	)
 
 	if err != nil {
 		t.Fatalf("Failed to insert race record: %v", err)
 	}
+
+	if sportsEvent.NumOfParticipants > 0 {
+		_, err = r.Exec(getSportsEventQueriesForTest()[insertEventParticipants], &sportsEvent.NumOfParticipants, &sportsEvent.Id)
+		if err != nil {
+			t.Fatalf("Failed to insert participant records: %v", err)
+		}
+	}
 }
 
 func TestListEvents_Default(t *testing.T) {
@@ -65,7 +116,13 @@ func TestListEvents_Default(t *testing.T) {
 
 	// Set up a new SportsService with the test database
 	sportsRepo := db.NewSportsRepo(sportsDB)
-	sportsService := service.NewSportsService(sportsRepo)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
 
 	timeTest1, err := time.Parse(time.RFC3339, "1992-04-05T00:00:00Z")
 	timeTest2, err := time.Parse(time.RFC3339, "4452-04-05T00:00:00Z")
@@ -142,7 +199,13 @@ func TestListEvents_WithIdsFilter(t *testing.T) {
 
 	// Set up a new SportsService with the test database
 	sportsRepo := db.NewSportsRepo(sportsDB)
-	sportsService := service.NewSportsService(sportsRepo)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
 
 	timeTest1, err := time.Parse(time.RFC3339, "1992-04-05T00:00:00Z")
 	timeTest2, err := time.Parse(time.RFC3339, "4452-04-05T00:00:00Z")
@@ -217,7 +280,13 @@ func TestListEvents_WithIdsFilterButOneIdNotExisted(t *testing.T) {
 
 	// Set up a new SportsService with the test database
 	sportsRepo := db.NewSportsRepo(sportsDB)
-	sportsService := service.NewSportsService(sportsRepo)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
 
 	timeTest1, err := time.Parse(time.RFC3339, "1992-04-05T00:00:00Z")
 	timeTest2, err := time.Parse(time.RFC3339, "4452-04-05T00:00:00Z")
@@ -274,3 +343,102 @@ func TestListEvents_WithIdsFilterButOneIdNotExisted(t *testing.T) {
 		t.Errorf("Response did not match expected value. Got %v, expected %v", resp.Events, expectedEvents)
 	}
 }
+
+func TestGetEvent_Default(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	defer sportsDB.Close()
+
+	//clear the data
+	sportsDB.Exec(getSportsEventQueriesForTest()[clearAllDataSportsEvents])
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	timeTest1, err := time.Parse(time.RFC3339, "1992-04-05T00:00:00Z")
+	timeTest2, err := time.Parse(time.RFC3339, "4452-04-05T00:00:00Z")
+	// Insert event records into the sports table
+	InsertNewSportsEvent(&sports.Event{
+		Id:                  1,
+		Name:                "Horse Racing",
+		CityAddress:         "Davismouth",
+		NumOfParticipants:   826,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, sportsDB, t)
+	InsertNewSportsEvent(&sports.Event{
+		Id:                  2,
+		Name:                "Human Racing",
+		CityAddress:         "Brisbane",
+		NumOfParticipants:   123,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}, sportsDB, t)
+
+	// Set up a new context and request for the GetEvent RPC
+	ctx := context.Background()
+	req := &sports.GetEventRequest{Id: 2}
+
+	// Call the GetEvent RPC
+	resp, err := sportsService.GetEvent(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+
+	// Check that the response contains the expected data
+	expectedEvent := &sports.Event{
+		Id:                  2,
+		Name:                "Human Racing",
+		CityAddress:         "Brisbane",
+		NumOfParticipants:   123,
+		AdvertisedStartTime: timestamppb.New(timeTest2),
+	}
+	if !proto.Equal(resp, expectedEvent) {
+		t.Errorf("Response did not match expected value. Got %v, expected %v", resp, expectedEvent)
+	}
+}
+
+func TestGetEvent_IdIsNotFound(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	defer sportsDB.Close()
+
+	//clear the data
+	sportsDB.Exec(getSportsEventQueriesForTest()[clearAllDataSportsEvents])
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	timeTest1, err := time.Parse(time.RFC3339, "1992-04-05T00:00:00Z")
+	// Insert an event record into the sports table
+	InsertNewSportsEvent(&sports.Event{
+		Id:                  1,
+		Name:                "Horse Racing",
+		CityAddress:         "Davismouth",
+		NumOfParticipants:   826,
+		AdvertisedStartTime: timestamppb.New(timeTest1),
+	}, sportsDB, t)
+
+	// Set up a new context and request for the GetEvent RPC
+	ctx := context.Background()
+	req := &sports.GetEventRequest{Id: 99}
+
+	// Call the GetEvent RPC
+	_, err = sportsService.GetEvent(ctx, req)
+	// Check that the error is the expected type
+	if grpc.Code(err) != codes.NotFound {
+		t.Errorf("Expected error code %v but got %v", codes.NotFound, grpc.Code(err))
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+func TestUpdateEventStatus_Transitions(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	// Event 1 has started, event 2 is in the future
+	timeTest1, err := time.Parse(time.RFC3339, "2004-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeTest2, err := time.Parse(time.RFC3339, "4452-04-05T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Test Event 1", AdvertisedStartTime: timestamppb.New(timeTest1)}, sportsDB, t)
+	InsertNewSportsEvent(&sports.Event{Id: 2, Name: "Test Event 2", AdvertisedStartTime: timestamppb.New(timeTest2)}, sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	ctx := context.Background()
+
+	// Without a stored status an event is SCHEDULED, even once its start time has passed
+	for id, expected := range map[int64]sports.EventStatus{1: sports.EventStatus_SCHEDULED, 2: sports.EventStatus_SCHEDULED} {
+		event, err := sportsService.GetEvent(ctx, &sports.GetEventRequest{Id: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Status != expected {
+			t.Errorf("Expected event %d to be %v but got %v", id, expected, event.Status)
+		}
+	}
+
+	// Legal transitions are stored
+	for _, step := range []struct {
+		id     int64
+		status sports.EventStatus
+	}{
+		{1, sports.EventStatus_SUSPENDED},
+		{1, sports.EventStatus_LIVE},
+		{1, sports.EventStatus_FINISHED},
+		{2, sports.EventStatus_CANCELLED},
+	} {
+		event, err := sportsService.UpdateEventStatus(ctx, &sports.UpdateEventStatusRequest{Id: step.id, Status: step.status})
+		if err != nil {
+			t.Fatalf("Failed to move event %d to %v: %v", step.id, step.status, err)
+		}
+		if event.Status != step.status {
+			t.Errorf("Expected event %d to be %v but got %v", step.id, step.status, event.Status)
+		}
+	}
+
+	// Terminal statuses cannot be left, and unknown events are not found
+	for _, tc := range []struct {
+		in   *sports.UpdateEventStatusRequest
+		code codes.Code
+	}{
+		{&sports.UpdateEventStatusRequest{Id: 1, Status: sports.EventStatus_LIVE}, codes.FailedPrecondition},
+		{&sports.UpdateEventStatusRequest{Id: 2, Status: sports.EventStatus_SCHEDULED}, codes.FailedPrecondition},
+		{&sports.UpdateEventStatusRequest{Id: 99, Status: sports.EventStatus_LIVE}, codes.NotFound},
+	} {
+		_, err = sportsService.UpdateEventStatus(ctx, tc.in)
+		if grpc.Code(err) != tc.code {
+			t.Errorf("Expected error code %v for %v but got %v", tc.code, tc.in, grpc.Code(err))
+		}
+	}
+
+	// Stored statuses can be filtered on, along with the SCHEDULED events that have none
+	InsertNewSportsEvent(&sports.Event{Id: 3, Name: "Test Event 3", AdvertisedStartTime: timestamppb.New(timeTest1)}, sportsDB, t)
+	resp, err := sportsService.ListEvents(ctx, &sports.ListEventsRequest{Filter: &sports.ListEventsRequestFilter{
+		Statuses: []sports.EventStatus{sports.EventStatus_SCHEDULED, sports.EventStatus_CANCELLED},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for _, event := range resp.Events {
+		ids = append(ids, event.Id)
+	}
+	if !equalIds(ids, []int64{2, 3}) {
+		t.Errorf("Expected events [2 3] but got %v", ids)
+	}
+}
@@ -0,0 +1,181 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sports/db"
+	"sports/proto/sports"
+	"sports/service"
+)
+
+func TestWatchEvents_Changes(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Test Event 1", AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour))}, sportsDB, t)
+	InsertNewSportsEvent(&sports.Event{Id: 2, Name: "Test Event 2", AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour))}, sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	// Serve the sports service over an in-memory connection
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	sports.RegisterSportsServer(grpcServer, sportsService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Only the events yet to start are watched
+	stream, err := sports.NewSportsClient(conn).WatchEvents(ctx, &sports.WatchEventsRequest{
+		Filter: &sports.ListEventsRequestFilter{Statuses: []sports.EventStatus{sports.EventStatus_SCHEDULED, sports.EventStatus_SUSPENDED}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectChange := func(changeType sports.EventChangeType, eventID int64, eventStatus sports.EventStatus) {
+		t.Helper()
+
+		change, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Expected %v change for event %d but got %v", changeType, eventID, err)
+		}
+
+		if change.Type != changeType || change.Event.Id != eventID || change.Event.Status != eventStatus {
+			t.Errorf("Expected %v change for event %d in %v but got %v change for event %d in %v",
+				changeType, eventID, eventStatus, change.Type, change.Event.Id, change.Event.Status)
+		}
+	}
+
+	// The watched events are sent first
+	expectChange(sports.EventChangeType_CREATED, 1, sports.EventStatus_SCHEDULED)
+	expectChange(sports.EventChangeType_CREATED, 2, sports.EventStatus_SCHEDULED)
+
+	// Changes made through the service are sent straight away
+	_, err = sportsService.UpdateEventStatus(ctx, &sports.UpdateEventStatusRequest{Id: 1, Status: sports.EventStatus_SUSPENDED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectChange(sports.EventChangeType_UPDATED, 1, sports.EventStatus_SUSPENDED)
+
+	// Changes made outside the service are picked up by the next poll, and event 2 stops matching
+	// once it goes live
+	if _, err := sportsDB.Exec(`UPDATE events SET status = ? WHERE id = 2`, sports.EventStatus_LIVE); err != nil {
+		t.Fatal(err)
+	}
+	expectChange(sports.EventChangeType_DELETED, 2, sports.EventStatus_SCHEDULED)
+}
+
+func TestWatchChanges_Signals(t *testing.T) {
+	// Set up a test database with for testing
+	sportsDB, err := NewTestSportDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sportsDB.Close()
+
+	InsertNewSportsEvent(&sports.Event{Id: 1, Name: "Test Event 1", AdvertisedStartTime: timestamppb.New(time.Now().Add(time.Hour))}, sportsDB, t)
+
+	// Set up a new SportsService with the test database
+	sportsRepo := db.NewSportsRepo(sportsDB)
+	competitionsRepo := db.NewCompetitionsRepo(sportsDB)
+	eventsRepo := db.NewEventsRepo(sportsDB)
+	marketsRepo := db.NewMarketsRepo(sportsDB)
+	selectionsRepo := db.NewSelectionsRepo(sportsDB)
+	scoresRepo := db.NewScoresRepo(sportsDB)
+	participantsRepo := db.NewParticipantsRepo(sportsDB)
+	sportsService := service.NewSportsService(sportsRepo, competitionsRepo, eventsRepo, marketsRepo, selectionsRepo, scoresRepo, participantsRepo)
+
+	// Serve the sports service over an in-memory connection
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	sports.RegisterSportsServer(grpcServer, sportsService)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := sports.NewSportsClient(conn).WatchChanges(ctx, &sports.WatchChangesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signals := make(chan error, 10)
+	go func() {
+		for {
+			_, err := stream.Recv()
+			signals <- err
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	expectSignal := func(what string) {
+		t.Helper()
+
+		select {
+		case err := <-signals:
+			if err != nil {
+				t.Fatalf("Expected a signal %s but got %v", what, err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Expected a signal %s", what)
+		}
+	}
+
+	// A signal is sent once watching, and none while nothing changes
+	expectSignal("once watching")
+
+	select {
+	case err := <-signals:
+		t.Fatalf("Expected no signal while nothing changes but got one (%v)", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Changes made through the service are signalled straight away
+	_, err = sportsService.UpdateEventStatus(ctx, &sports.UpdateEventStatusRequest{Id: 1, Status: sports.EventStatus_SUSPENDED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectSignal("for the suspended event")
+}